@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/solar"
+)
+
+type evPlanReq struct {
+	estimateReq
+	NeedKWh   float64 `json:"needKWh"`
+	ChargerKW float64 `json:"chargerKW"`
+	// Start defaults to now; both accept RFC 3339 or local "2006-01-02T15:04".
+	Start    string `json:"start,omitempty"`
+	Deadline string `json:"deadline"`
+}
+
+func parseLocalTime(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.In(loc), nil
+	}
+	return time.ParseInLocation("2006-01-02T15:04", s, loc)
+}
+
+// evPlanHandler schedules an EV charge over the forecast, putting it in the
+// hours with the most solar surplus and reporting what must come from the
+// grid.
+func (h *BaseHandler) evPlanHandler(w http.ResponseWriter, r *http.Request) {
+	var req evPlanReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad json", http.StatusBadRequest)
+		return
+	}
+
+	site, err := h.resolveSite(req.estimateReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	nowLocal := time.Now().In(site.loc)
+	ev := solar.EVCharge{NeedKWh: req.NeedKWh, ChargerKW: req.ChargerKW, Start: nowLocal}
+	if req.Start != "" {
+		if ev.Start, err = parseLocalTime(req.Start, site.loc); err != nil {
+			http.Error(w, "bad start time", http.StatusBadRequest)
+			return
+		}
+	}
+	if ev.Deadline, err = parseLocalTime(req.Deadline, site.loc); err != nil {
+		http.Error(w, "bad deadline", http.StatusBadRequest)
+		return
+	}
+	if err := ev.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	today := startOfDay(nowLocal)
+	first, last := startOfDay(ev.Start), startOfDay(ev.Deadline)
+	if first.Before(today) || !last.Before(today.AddDate(0, 0, maxForecastDays)) {
+		http.Error(w, "charging window must fall within the forecast", http.StatusBadRequest)
+		return
+	}
+
+	members, err := site.fetchForecast(r.Context(), req.estimateReq, first, last)
+	if err != nil {
+		http.Error(w, "weather fetch failed", http.StatusBadGateway)
+		return
+	}
+	if daySite, ok := h.cachedSnowCover(r.Context(), req.estimateReq, site, first); ok {
+		site = daySite
+	} else {
+		site = site.withSnowCover(req.estimateReq, h.snowHistory(r.Context(), req.estimateReq, site, first, members[0]), first)
+		h.cacheSnowCover(r.Context(), req.estimateReq, site, first, nowLocal)
+	}
+	res, err := site.calculateEnsemble(req.estimateReq, members)
+	if err != nil {
+		http.Error(w, "calc failed", http.StatusInternalServerError)
+		return
+	}
+
+	resp := map[string]any{
+		"panel":    req.Panel,
+		"lat":      req.Lat,
+		"lon":      req.Lon,
+		"timezone": site.tz,
+		"bands":    res.bands,
+	}
+	site.applyGrid(res).addTo(resp)
+
+	var rate func(time.Time) float64
+	if site.tariff != nil {
+		rate = func(t time.Time) float64 {
+			imp, _ := site.tariff.Rates(t)
+			return imp
+		}
+	}
+	plan, err := solar.PlanEVCharging(res.points, ev, site.load != nil, rate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp["plan"] = plan
+
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/finance"
+	"github.com/joseph-gunnarsson/solar-cast/internals/solar"
+)
+
+type financeReq struct {
+	lifetimeReq
+	Finance finance.Inputs `json:"finance"`
+}
+
+// financeHandler values the lifetime projection and returns payback, NPV,
+// IRR and LCOE alongside the year-by-year cash flows.
+func (h *BaseHandler) financeHandler(w http.ResponseWriter, r *http.Request) {
+	var req financeReq
+	// Without a figure, all generation is assumed to offset purchases.
+	req.Finance.SelfConsumption = 1
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad json", http.StatusBadRequest)
+		return
+	}
+	if err := req.Finance.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	site, err := h.resolveSite(req.estimateReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	deg := site.degradation()
+	years, err := req.years(deg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	baseline, year, err := h.resolveBaseline(r.Context(), req.lifetimeReq, site)
+	if err != nil {
+		http.Error(w, "baseline calc failed", http.StatusBadGateway)
+		return
+	}
+	projection, err := solar.ProjectLifetime(baseline, deg, years)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	analysis, err := finance.Analyze(req.Finance, projection)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := map[string]any{
+		"panel":        req.Panel,
+		"lat":          req.Lat,
+		"lon":          req.Lon,
+		"baselineKWh":  baseline,
+		"degradation":  deg,
+		"inputs":       req.Finance,
+		"paybackYears": analysis.PaybackYears,
+		"npv":          analysis.NPV,
+		"irr":          analysis.IRR,
+		"lcoe":         analysis.LCOE,
+		"years":        analysis.Years,
+	}
+	if year != 0 {
+		resp["baselineYear"] = year
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
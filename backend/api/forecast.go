@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+const (
+	defaultForecastDays = 7
+	maxForecastDays     = 16
+)
+
+type forecastReq struct {
+	estimateReq
+	Days int `json:"days,omitempty"`
+}
+
+type dayTotals struct {
+	TotalWh     float64 `json:"totalWh"`
+	TotalLowWh  float64 `json:"totalLowWh"`
+	TotalHighWh float64 `json:"totalHighWh"`
+	CurtailedWh float64 `json:"curtailedWh,omitempty"`
+	SnowLossWh  float64 `json:"snowLossWh,omitempty"`
+	Value       float64 `json:"value,omitempty"`
+}
+
+// forecastHandler returns one estimate per day starting today. Each day shares
+// its cache entry with estimateHandler, so only uncached days hit Open-Meteo.
+func (h *BaseHandler) forecastHandler(w http.ResponseWriter, r *http.Request) {
+	var req forecastReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad json", http.StatusBadRequest)
+		return
+	}
+	if req.Days == 0 {
+		req.Days = defaultForecastDays
+	}
+	if req.Days < 1 || req.Days > maxForecastDays {
+		http.Error(w, "days out of range", http.StatusBadRequest)
+		return
+	}
+
+	site, err := h.resolveSite(req.estimateReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	nowLocal := time.Now().In(site.loc)
+	today := startOfDay(nowLocal)
+
+	days := make([]json.RawMessage, req.Days)
+	daySites := make([]estimateSite, req.Days)
+	known := make([]bool, req.Days)
+	var missing []int
+	for i := range days {
+		day := today.AddDate(0, 0, i)
+		daySites[i], known[i] = h.cachedSnowCover(r.Context(), req.estimateReq, site, day)
+		if !known[i] {
+			missing = append(missing, i)
+			continue
+		}
+		if blob, ok := h.cacheGet(r.Context(), daySites[i].cacheKey(req.estimateReq, day)); ok {
+			days[i] = blob
+		} else {
+			missing = append(missing, i)
+		}
+	}
+
+	if len(missing) > 0 {
+		first := today.AddDate(0, 0, missing[0])
+		last := today.AddDate(0, 0, missing[len(missing)-1])
+		members, err := site.fetchForecast(r.Context(), req.estimateReq, first, last)
+		if err != nil {
+			http.Error(w, "weather fetch failed", http.StatusBadGateway)
+			return
+		}
+		var history *clients.WeatherPack
+
+		for _, i := range missing {
+			day := today.AddDate(0, 0, i)
+			daySite := daySites[i]
+			if !known[i] {
+				if history == nil {
+					wp := h.snowHistory(r.Context(), req.estimateReq, site, first, members[0])
+					history = &wp
+				}
+				daySite = site.withSnowCover(req.estimateReq, *history, day)
+				h.cacheSnowCover(r.Context(), req.estimateReq, daySite, day, nowLocal)
+			}
+			resp, err := buildDayEstimate(req.estimateReq, daySite, day, membersForDay(members, day))
+			if err != nil {
+				http.Error(w, "calc failed", http.StatusInternalServerError)
+				return
+			}
+			blob, err := json.Marshal(resp)
+			if err != nil {
+				http.Error(w, "calc failed", http.StatusInternalServerError)
+				return
+			}
+			days[i] = blob
+			h.cacheSet(r.Context(), daySite.cacheKey(req.estimateReq, day), blob, estimateTTL(resp, day, nowLocal))
+		}
+	}
+
+	var total dayTotals
+	for _, blob := range days {
+		var t dayTotals
+		if err := json.Unmarshal(blob, &t); err == nil {
+			total.TotalWh += t.TotalWh
+			total.TotalLowWh += t.TotalLowWh
+			total.TotalHighWh += t.TotalHighWh
+			total.CurtailedWh += t.CurtailedWh
+			total.SnowLossWh += t.SnowLossWh
+			total.Value += t.Value
+		}
+	}
+
+	switch len(missing) {
+	case 0:
+		w.Header().Set("X-Cache", "HIT")
+	case req.Days:
+		w.Header().Set("X-Cache", "MISS")
+	default:
+		w.Header().Set("X-Cache", "PARTIAL")
+	}
+	resp := map[string]any{
+		"panel":       req.Panel,
+		"lat":         req.Lat,
+		"lon":         req.Lon,
+		"timezone":    site.tz,
+		"startDate":   today.Format("2006-01-02"),
+		"days":        days,
+		"totalWh":     total.TotalWh,
+		"totalLowWh":  total.TotalLowWh,
+		"totalHighWh": total.TotalHighWh,
+	}
+	if site.exportLimitW != nil {
+		resp["curtailedWh"] = total.CurtailedWh
+	}
+	if site.tariff != nil {
+		resp["value"] = total.Value
+	}
+	if total.SnowLossWh > 0 {
+		resp["snowLossWh"] = total.SnowLossWh
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -1,7 +1,10 @@
 package api
 
 import (
+	"context"
+	"crypto/sha1"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,6 +13,7 @@ import (
 	"time"
 
 	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+	"github.com/joseph-gunnarsson/solar-cast/internals/finance"
 	"github.com/joseph-gunnarsson/solar-cast/internals/solar"
 	"github.com/redis/go-redis/v9"
 	"github.com/ringsaturn/tzf"
@@ -17,22 +21,22 @@ import (
 
 type BaseHandler struct {
 	solarPanelData   atomic.Value
+	inverterData     atomic.Value
 	defaultPanelData map[string]solar.SolarPanelData
 	redisClient      *redis.Client
 }
 
-func NewBaseHandler(solarPanelData map[string]solar.SolarPanelData, redisClient *redis.Client) *BaseHandler {
-	defaults := map[string]solar.SolarPanelData{
-		"Mono-Default-400": {ModelNo: "Mono-Default-400", MaximumPowerPmax: 400, TemperatureCoefficientPmax: -0.0035, NOCT_Temp: 45},
-		"Poly-Default-340": {ModelNo: "Poly-Default-340", MaximumPowerPmax: 340, TemperatureCoefficientPmax: -0.0040, NOCT_Temp: 45},
-		"Thin-Default-150": {ModelNo: "Thin-Default-150", MaximumPowerPmax: 150, TemperatureCoefficientPmax: -0.0025, NOCT_Temp: 47},
-	}
-
+func NewBaseHandler(
+	solarPanelData map[string]solar.SolarPanelData,
+	inverterData map[string]solar.InverterData,
+	redisClient *redis.Client,
+) *BaseHandler {
 	h := &BaseHandler{
-		defaultPanelData: defaults,
+		defaultPanelData: solar.DefaultPanelData(),
 		redisClient:      redisClient,
 	}
 	h.solarPanelData.Store(solarPanelData)
+	h.inverterData.Store(inverterData)
 	return h
 }
 
@@ -84,31 +88,290 @@ func (h *BaseHandler) locationAutocompleteHandler(w http.ResponseWriter, r *http
 }
 
 type estimateReq struct {
-	Panel    string  `json:"panel"`
-	Lat      float64 `json:"lat"`
-	Lon      float64 `json:"lon"`
-	Timezone *string `json:"timezone,omitempty"`
+	Panel    string   `json:"panel"`
+	Lat      float64  `json:"lat"`
+	Lon      float64  `json:"lon"`
+	Timezone *string  `json:"timezone,omitempty"`
+	Tilt     *float64 `json:"tilt,omitempty"`
+	Azimuth  *float64 `json:"azimuth,omitempty"`
+	Albedo   *float64 `json:"albedo,omitempty"`
+
+	Decomposition    solar.DecompositionModel   `json:"decomposition,omitempty"`
+	TemperatureModel solar.TemperatureModelName `json:"temperatureModel,omitempty"`
+	IAM              solar.IAMModel             `json:"iam,omitempty"`
+	Spectral         bool                       `json:"spectral,omitempty"`
+	Horizon          []solar.HorizonPoint       `json:"horizon,omitempty"`
+	NearShading      *nearShadingReq            `json:"nearShading,omitempty"`
+	System           *solar.System              `json:"system,omitempty"`
+	Arrays           []subArrayReq              `json:"arrays,omitempty"`
+	// ExportLimitW caps grid export for sites without a system, where it
+	// applies to the DC output of the arrays; with a system, set
+	// system.exportLimitW instead. A single panel is not a site, so it needs
+	// arrays with counts.
+	ExportLimitW *float64 `json:"exportLimitW,omitempty"`
+	// Tracker mounts every array on trackers; Tilt and Azimuth are then ignored.
+	Tracker *solar.Tracker `json:"tracker,omitempty"`
+	// MountingHeight (m) and Bifaciality shape the rear-side gain of bifacial
+	// panels; Bifaciality overrides the panel datasheet for every array.
+	MountingHeight *float64 `json:"mountingHeight,omitempty"`
+	Bifaciality    *float64 `json:"bifaciality,omitempty"`
+	// SnowStrings sets the steps of snow loss, see solar.Array.
+	SnowStrings int `json:"snowStrings,omitempty"`
+	// Load nets each hour against household consumption.
+	Load *loadReq `json:"load,omitempty"`
+	// Battery is dispatched against the load, so it requires one.
+	Battery *solar.Battery `json:"battery,omitempty"`
+	// Tariff prices each hour. Without a load, SelfConsumption (default 1) is
+	// the share of output that offsets imports rather than being exported.
+	Tariff          *finance.Tariff `json:"tariff,omitempty"`
+	SelfConsumption *float64        `json:"selfConsumption,omitempty"`
 }
 
-func (h *BaseHandler) estimateHandler(w http.ResponseWriter, r *http.Request) {
-	var req estimateReq
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "bad json", http.StatusBadRequest)
-		return
+// subArrayReq describes one orientation group when a site has several. Its
+// panel replaces the request's top-level panel.
+type subArrayReq struct {
+	Name    string   `json:"name,omitempty"`
+	Panel   string   `json:"panel"`
+	Count   int      `json:"count"`
+	Tilt    *float64 `json:"tilt,omitempty"`
+	Azimuth *float64 `json:"azimuth,omitempty"`
+	Albedo  *float64 `json:"albedo,omitempty"`
+	// NearShading overrides the site-wide obstacle geometry for this array.
+	NearShading *nearShadingReq `json:"nearShading,omitempty"`
+}
+
+// nearShadingReq accepts obstacles inline, as a GeoJSON FeatureCollection
+// of points around the site, or both.
+type nearShadingReq struct {
+	solar.NearShading
+	GeoJSON json.RawMessage `json:"geojson,omitempty"`
+}
+
+// loadReq picks a synthetic profile scaled to AnnualKWh, or carries meter
+// data as CSV text (see solar.ParseLoadCSV).
+type loadReq struct {
+	Profile   solar.LoadShape `json:"profile,omitempty"`
+	AnnualKWh float64         `json:"annualKWh,omitempty"`
+	CSV       string          `json:"csv,omitempty"`
+}
+
+func (l loadReq) profile() (solar.LoadProfile, error) {
+	if l.CSV != "" {
+		return solar.ParseLoadCSV(strings.NewReader(l.CSV))
 	}
-	data := h.getData()
-	p, ok := data[req.Panel]
-	if !ok {
-		p, ok = h.defaultPanelData[req.Panel]
-		if !ok {
-			http.Error(w, "unknown panel", http.StatusBadRequest)
-			return
+	if l.Profile == "" {
+		l.Profile = solar.LoadResidential
+	}
+	return solar.SyntheticLoad(l.Profile, l.AnnualKWh)
+}
+
+const (
+	maxSubArrays      = 10
+	maxMountingHeight = 20
+	maxSnowStrings    = 6
+)
+
+func (req estimateReq) array() (solar.Array, error) {
+	return req.buildArray(req.Tilt, req.Azimuth, req.Albedo, req.NearShading)
+}
+
+func (req estimateReq) buildArray(tilt, azimuth, albedo *float64, ns *nearShadingReq) (solar.Array, error) {
+	arr := solar.Array{
+		Azimuth:          solar.DefaultAzimuth(req.Lat),
+		Albedo:           solar.DefaultAlbedo,
+		Decomposition:    req.Decomposition,
+		TemperatureModel: req.TemperatureModel,
+		IAM:              req.IAM,
+		Spectral:         req.Spectral,
+	}
+	if tilt != nil {
+		arr.Tilt = *tilt
+	}
+	if azimuth != nil {
+		arr.Azimuth = *azimuth
+	}
+	if albedo != nil {
+		arr.Albedo = *albedo
+	}
+
+	if arr.Tilt < 0 || arr.Tilt > 90 {
+		return arr, errors.New("tilt out of range")
+	}
+	if arr.Azimuth < 0 || arr.Azimuth >= 360 {
+		return arr, errors.New("azimuth out of range")
+	}
+	if arr.Albedo < 0 || arr.Albedo > 1 {
+		return arr, errors.New("albedo out of range")
+	}
+	if !arr.Decomposition.Valid() {
+		return arr, errors.New("unknown decomposition model")
+	}
+	if !arr.TemperatureModel.Valid() {
+		return arr, errors.New("unknown temperature model")
+	}
+	if !arr.IAM.Valid() {
+		return arr, errors.New("unknown iam model")
+	}
+	if req.Tracker != nil {
+		if err := req.Tracker.Validate(); err != nil {
+			return arr, err
+		}
+		arr.Tracker = req.Tracker
+	}
+	if req.MountingHeight != nil {
+		if *req.MountingHeight < 0 || *req.MountingHeight > maxMountingHeight {
+			return arr, errors.New("mounting height out of range")
+		}
+		arr.MountingHeight = *req.MountingHeight
+	}
+	if req.SnowStrings < 0 || req.SnowStrings > maxSnowStrings {
+		return arr, errors.New("snowStrings out of range")
+	}
+	arr.SnowStrings = req.SnowStrings
+	if len(req.Horizon) > 0 {
+		h, err := solar.NewHorizon(req.Horizon)
+		if err != nil {
+			return arr, err
 		}
+		arr.Horizon = h
 	}
+	if ns != nil {
+		shading := ns.NearShading
+		if len(ns.GeoJSON) > 0 {
+			obs, err := solar.ParseObstaclesGeoJSON(ns.GeoJSON, req.Lat, req.Lon)
+			if err != nil {
+				return arr, err
+			}
+			shading.Obstacles = append(append([]solar.Obstacle(nil), shading.Obstacles...), obs...)
+		}
+		if err := shading.Validate(); err != nil {
+			return arr, err
+		}
+		arr.NearShading = &shading
+	}
+	return arr, nil
+}
 
+type estimateSite struct {
+	panel           solar.SolarPanelData
+	arr             solar.Array
+	subs            []solar.SubArray
+	sys             *solar.System
+	load            *solar.LoadProfile
+	battery         *solar.Battery
+	tariff          *finance.Tariff
+	selfConsumption float64
+	// bifaciality is the request's override of the panel value.
+	bifaciality *float64
+	// exportLimitW comes from the system or, without one, the request.
+	exportLimitW *float64
+	tz           string
+	loc          *time.Location
+}
+
+func (h *BaseHandler) lookupPanel(name string) (solar.SolarPanelData, bool) {
+	if p, ok := h.getData()[name]; ok {
+		return p, true
+	}
+	p, ok := h.defaultPanelData[name]
+	return p, ok
+}
+
+func (h *BaseHandler) resolveSite(req estimateReq) (estimateSite, error) {
 	if req.Lat < -90 || req.Lat > 90 || req.Lon < -180 || req.Lon > 180 {
-		http.Error(w, "lat/lon out of range", http.StatusBadRequest)
-		return
+		return estimateSite{}, errors.New("lat/lon out of range")
+	}
+
+	var site estimateSite
+	if len(req.Arrays) > 0 {
+		subs, err := h.resolveSubArrays(req)
+		if err != nil {
+			return estimateSite{}, err
+		}
+		site.subs = subs
+	} else {
+		p, ok := h.lookupPanel(req.Panel)
+		if !ok {
+			return estimateSite{}, errors.New("unknown panel")
+		}
+		arr, err := req.array()
+		if err != nil {
+			return estimateSite{}, err
+		}
+		site.panel, site.arr = p, arr
+	}
+
+	if req.Bifaciality != nil {
+		b := *req.Bifaciality
+		if b < 0 || b > 1 {
+			return estimateSite{}, errors.New("bifaciality must be between 0 and 1")
+		}
+		site.panel.Bifaciality = b
+		for i := range site.subs {
+			site.subs[i].Data.Bifaciality = b
+		}
+		site.bifaciality = &b
+	}
+
+	if req.System != nil {
+		s := *req.System
+		if s.Inverter.Model != "" {
+			spec, ok := h.getInverters()[s.Inverter.Model]
+			if !ok {
+				return estimateSite{}, errors.New("unknown inverter")
+			}
+			s.Inverter.Spec = &spec
+		}
+		validate := s.Validate
+		if len(site.subs) > 0 {
+			validate = s.ValidateInverter
+		}
+		if err := validate(); err != nil {
+			return estimateSite{}, err
+		}
+		site.sys = &s
+		site.exportLimitW = s.ExportLimitW
+	}
+	if req.ExportLimitW != nil {
+		switch {
+		case site.sys != nil:
+			return estimateSite{}, errors.New("set exportLimitW on the system")
+		case len(site.subs) == 0:
+			return estimateSite{}, errors.New("exportLimitW needs a system or arrays with counts")
+		case *req.ExportLimitW < 0:
+			return estimateSite{}, errors.New("export limit must not be negative")
+		}
+		site.exportLimitW = req.ExportLimitW
+	}
+
+	if req.Load != nil {
+		load, err := req.Load.profile()
+		if err != nil {
+			return estimateSite{}, err
+		}
+		site.load = &load
+	}
+	if req.Battery != nil {
+		if site.load == nil {
+			return estimateSite{}, errors.New("battery needs a load profile")
+		}
+		if err := req.Battery.Validate(); err != nil {
+			return estimateSite{}, err
+		}
+		site.battery = req.Battery
+	}
+	if req.Tariff != nil {
+		if err := req.Tariff.Validate(); err != nil {
+			return estimateSite{}, err
+		}
+		site.tariff = req.Tariff
+	}
+	site.selfConsumption = 1
+	if req.SelfConsumption != nil {
+		if *req.SelfConsumption < 0 || *req.SelfConsumption > 1 {
+			return estimateSite{}, errors.New("selfConsumption must be between 0 and 1")
+		}
+		site.selfConsumption = *req.SelfConsumption
 	}
 
 	tz := "UTC"
@@ -123,41 +386,282 @@ func (h *BaseHandler) estimateHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		loc = time.UTC
 	}
-	nowLocal := time.Now().In(loc)
-	day := time.Date(nowLocal.Year(), nowLocal.Month(), nowLocal.Day(), 0, 0, 0, 0, loc)
+	site.tz, site.loc = tz, loc
+	return site, nil
+}
+
+func (h *BaseHandler) resolveSubArrays(req estimateReq) ([]solar.SubArray, error) {
+	if len(req.Arrays) > maxSubArrays {
+		return nil, fmt.Errorf("at most %d arrays per site", maxSubArrays)
+	}
+	subs := make([]solar.SubArray, 0, len(req.Arrays))
+	for i, a := range req.Arrays {
+		p, ok := h.lookupPanel(a.Panel)
+		if !ok {
+			return nil, fmt.Errorf("array %d: unknown panel", i)
+		}
+		if a.Count <= 0 {
+			return nil, fmt.Errorf("array %d: count must be positive", i)
+		}
+		ns := a.NearShading
+		if ns == nil {
+			ns = req.NearShading
+		}
+		arr, err := req.buildArray(a.Tilt, a.Azimuth, a.Albedo, ns)
+		if err != nil {
+			return nil, fmt.Errorf("array %d: %w", i, err)
+		}
+		subs = append(subs, solar.SubArray{Name: a.Name, Panel: a.Panel, Count: a.Count, Array: arr, Data: p})
+	}
+	return subs, nil
+}
+
+// optionsHash fingerprints the model options so that new array parameters
+// invalidate cache keys without growing them.
+func (s estimateSite) optionsHash() string {
+	opts, _ := json.Marshal(struct {
+		Array           solar.Array
+		Subs            []solar.SubArray
+		System          *solar.System
+		Load            *solar.LoadProfile
+		Battery         *solar.Battery
+		Tariff          *finance.Tariff
+		SelfConsumption float64
+		Bifaciality     *float64
+		ExportLimitW    *float64
+	}{s.arr, s.subs, s.sys, s.load, s.battery, s.tariff, s.selfConsumption, s.bifaciality, s.exportLimitW})
+	sum := sha1.Sum(opts)
+	return fmt.Sprintf("%x", sum[:8])
+}
 
-	cacheKey := fmt.Sprintf(
-		"estimate:%s:%s:%s:%.6f:%.6f",
-		day.Format("2006-01-02"), tz, req.Panel, req.Lat, req.Lon,
+// cacheKey identifies one day of output, including the snow the day starts
+// under.
+func (s estimateSite) cacheKey(req estimateReq, day time.Time) string {
+	cover := make([]string, 0, len(s.subs)+1)
+	for _, c := range s.snowCoverage() {
+		cover = append(cover, fmt.Sprintf("%.2f", c))
+	}
+	return fmt.Sprintf(
+		"estimate:%s:%s:%s:%.6f:%.6f:%s:%s",
+		day.Format("2006-01-02"), s.tz, req.Panel, req.Lat, req.Lon, s.optionsHash(), strings.Join(cover, ","),
 	)
+}
 
-	if h.redisClient != nil {
-		if blob, err := h.redisClient.Get(r.Context(), cacheKey).Bytes(); err == nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.Header().Set("X-Cache", "HIT")
-			w.WriteHeader(http.StatusOK)
-			_, _ = w.Write(blob)
-			return
-		} else if err != redis.Nil {
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// dayTTL keeps a day's estimate until that day ends in the site's timezone.
+func dayTTL(day, now time.Time) time.Duration {
+	ttl := day.AddDate(0, 0, 1).Sub(now)
+	if ttl <= 0 {
+		ttl = time.Second
+	}
+	return ttl
+}
+
+// fallbackTTL keeps a day estimated with fixed bands only briefly, so the
+// ensemble is retried soon without every request going upstream meanwhile.
+const fallbackTTL = 5 * time.Minute
+
+// estimateTTL is how long a day's estimate stays cached.
+func estimateTTL(resp map[string]any, day, now time.Time) time.Duration {
+	ttl := dayTTL(day, now)
+	if resp["bands"] != solar.BandsEnsemble && ttl > fallbackTTL {
+		ttl = fallbackTTL
+	}
+	return ttl
+}
+
+func (h *BaseHandler) cacheGet(ctx context.Context, key string) ([]byte, bool) {
+	if h.redisClient == nil {
+		return nil, false
+	}
+	blob, err := h.redisClient.Get(ctx, key).Bytes()
+	if err != nil {
+		if err != redis.Nil {
 			log.Printf("redis get error: %v", err)
 		}
+		return nil, false
 	}
+	return blob, true
+}
 
-	wp, err := clients.FetchHourlyWeather(r.Context(), req.Lat, req.Lon, day, tz)
-	if err != nil {
-		http.Error(w, "weather fetch failed", http.StatusBadGateway)
+func (h *BaseHandler) cacheSet(ctx context.Context, key string, blob []byte, ttl time.Duration) {
+	if h.redisClient == nil {
 		return
 	}
+	if err := h.redisClient.Set(ctx, key, blob, ttl).Err(); err != nil {
+		log.Printf("redis set error: %v", err)
+	}
+}
+
+type siteResult struct {
+	points  []solar.HourlyPoint
+	arrays  []solar.SubArrayResult
+	system  *solar.SystemSummary
+	members int
+	bands   solar.BandSource
+}
+
+// calculate runs the array model (or each sub-array) and, when the request
+// describes a full system, converts the DC result to AC output.
+func (s estimateSite) calculate(req estimateReq, wp clients.WeatherPack) (siteResult, error) {
+	if len(s.subs) > 0 {
+		lossFactor := 1.0
+		if s.sys != nil {
+			lossFactor = s.sys.LossFactor()
+		}
+		arrays, dc, err := solar.CalculateSubArrays(s.subs, wp, req.Lat, req.Lon, lossFactor)
+		if err != nil {
+			return siteResult{}, err
+		}
+		res := siteResult{points: dc, arrays: arrays, bands: solar.BandsFixed}
+		if s.sys != nil {
+			rating, modules := solar.SubArrayRating(s.subs)
+			points, summary := s.sys.ConvertDC(dc, rating, modules)
+			res.points, res.system = points, &summary
+		}
+		return res, nil
+	}
 
-	points, totalBase, totalLow, totalHigh, err := solar.
-		CalculateHourlyOutputFromWeatherWithRange(p, wp, req.Lat)
+	points, _, _, _, err := solar.
+		CalculateHourlyOutputForArray(s.panel, wp, req.Lat, req.Lon, s.arr)
 	if err != nil {
-		http.Error(w, "calc failed", http.StatusInternalServerError)
-		return
+		return siteResult{}, err
+	}
+	if s.sys == nil {
+		return siteResult{points: points, bands: solar.BandsFixed}, nil
+	}
+	points, summary := solar.ApplySystem(s.panel, *s.sys, points)
+	return siteResult{points: points, system: &summary, bands: solar.BandsFixed}, nil
+}
+
+// calculateEnsemble runs calculate once per weather member and reduces the
+// curves to P90/P50/P10 bands. The system summary describes the control run.
+// A single member keeps the fixed bands of calculate.
+func (s estimateSite) calculateEnsemble(req estimateReq, members []clients.WeatherPack) (siteResult, error) {
+	if len(members) == 1 {
+		return s.calculate(req, members[0])
+	}
+
+	runs := make([]siteResult, len(members))
+	curves := make([][]solar.HourlyPoint, len(members))
+	for i, wp := range members {
+		r, err := s.calculate(req, wp)
+		if err != nil {
+			return siteResult{}, err
+		}
+		runs[i], curves[i] = r, r.points
+	}
+
+	points, err := solar.EnsembleQuantiles(curves)
+	if err != nil {
+		return siteResult{}, err
+	}
+	res := siteResult{points: points, system: runs[0].system, members: len(members), bands: solar.BandsEnsemble}
+
+	for a, arr := range runs[0].arrays {
+		for i, r := range runs {
+			curves[i] = r.arrays[a].Points
+		}
+		if arr.Points, err = solar.EnsembleQuantiles(curves); err != nil {
+			return siteResult{}, err
+		}
+		arr.TotalWh, _, _ = solar.Totals(arr.Points)
+		res.arrays = append(res.arrays, arr)
+	}
+	return res, nil
+}
+
+// gridResult holds what applyGrid found; addTo copies it into a response.
+type gridResult struct {
+	load        *solar.LoadSummary
+	battery     *solar.BatterySummary
+	curtailedWh *float64
+}
+
+// applyGrid nets the points against the site's load and battery, then
+// curtails anything above the export limit, when the site has them. Per-array
+// curves are then shared out of the final combined curve.
+func (s estimateSite) applyGrid(res siteResult) gridResult {
+	var g gridResult
+	if s.load != nil {
+		solar.ApplyLoad(res.points, *s.load)
+		if s.battery != nil {
+			b := solar.DispatchBattery(res.points, *s.battery)
+			g.battery = &b
+		}
+	}
+	if s.exportLimitW != nil {
+		c := solar.ApplyExportLimit(res.points, *s.exportLimitW, s.load != nil)
+		g.curtailedWh = &c
+		if res.system != nil {
+			res.system.CurtailedWh = c
+		}
+	}
+	if s.load != nil {
+		l := solar.SummarizeLoad(res.points)
+		g.load = &l
+	}
+	solar.ShareCombined(res.arrays, res.points)
+	return g
+}
+
+func (g gridResult) addTo(resp map[string]any) {
+	if g.load != nil {
+		resp["load"] = g.load
+	}
+	if g.battery != nil {
+		resp["battery"] = g.battery
+	}
+	if g.curtailedWh != nil {
+		resp["curtailedWh"] = *g.curtailedWh
+	}
+}
+
+// fetchForecast returns the forecast for [start, end] as weather members,
+// one per ensemble member so that forecasts carry P90/P50/P10 bands. When
+// the ensemble cannot be fetched it falls back to the single deterministic
+// run, whose bands are the fixed multipliers (solar.BandsFixed).
+func (s estimateSite) fetchForecast(ctx context.Context, req estimateReq, start, end time.Time) ([]clients.WeatherPack, error) {
+	members, err := clients.FetchEnsembleWeather(ctx, req.Lat, req.Lon, start, end, s.tz)
+	if err == nil {
+		return members, nil
+	}
+	log.Printf("ensemble fetch failed, falling back to fixed bands: %v", err)
+	wp, err := clients.FetchHourlyWeatherRange(ctx, req.Lat, req.Lon, start, end, s.tz)
+	if err != nil {
+		return nil, err
+	}
+	return []clients.WeatherPack{wp}, nil
+}
+
+func membersForDay(members []clients.WeatherPack, day time.Time) []clients.WeatherPack {
+	out := make([]clients.WeatherPack, len(members))
+	for i, wp := range members {
+		out[i] = wp.ForDay(day)
+	}
+	return out
+}
+
+func buildDayEstimate(req estimateReq, site estimateSite, day time.Time, members []clients.WeatherPack) (map[string]any, error) {
+	res, err := site.calculateEnsemble(req, members)
+	if err != nil {
+		return nil, err
+	}
+	wp := members[0]
+	grid := site.applyGrid(res)
+	totalBase, totalLow, totalHigh := solar.Totals(res.points)
+	var clearSkyWh, shadingLossWh, bifacialGainWh, snowLossWh float64
+	for _, p := range res.points {
+		clearSkyWh += p.ClearSkyWh
+		shadingLossWh += p.ShadingLossWh
+		bifacialGainWh += p.BifacialGainWh
+		snowLossWh += p.SnowLossWh
 	}
 
 	resp := map[string]any{
-		"panel":       req.Panel,
 		"lat":         req.Lat,
 		"lon":         req.Lon,
 		"timezone":    wp.Timezone,
@@ -165,22 +669,102 @@ func (h *BaseHandler) estimateHandler(w http.ResponseWriter, r *http.Request) {
 		"totalWh":     totalBase,
 		"totalLowWh":  totalLow,
 		"totalHighWh": totalHigh,
-		"points":      points,
+		"clearSkyWh":  clearSkyWh,
+		"bands":       res.bands,
+		"points":      res.points,
+	}
+	if shadingLossWh > 0 {
+		resp["shadingLossWh"] = shadingLossWh
+	}
+	if bifacialGainWh > 0 {
+		resp["bifacialGainWh"] = bifacialGainWh
+	}
+	if snowLossWh > 0 {
+		resp["snowLossWh"] = snowLossWh
+	}
+	grid.addTo(resp)
+	switch {
+	case site.tariff != nil && site.load != nil:
+		resp["value"] = finance.PriceLoadHours(res.points, *site.tariff)
+	case site.tariff != nil:
+		resp["value"] = finance.PriceHours(res.points, *site.tariff, site.selfConsumption)
+	}
+	if len(res.arrays) > 0 {
+		resp["arrays"] = res.arrays
+	} else {
+		resp["panel"] = req.Panel
+		resp["tilt"] = site.arr.Tilt
+		resp["azimuth"] = site.arr.Azimuth
+		resp["albedo"] = site.arr.Albedo
+	}
+	if res.system != nil {
+		resp["system"] = res.system
+	}
+	if res.members > 0 {
+		resp["ensembleMembers"] = res.members
 	}
 
-	if h.redisClient != nil {
-		nextMidnight := day.AddDate(0, 0, 1)
-		ttl := nextMidnight.Sub(nowLocal)
-		if ttl <= 0 {
-			ttl = time.Second
-		}
-		if blob, err := json.Marshal(resp); err == nil {
-			if err := h.redisClient.Set(r.Context(), cacheKey, blob, ttl).Err(); err != nil {
-				log.Printf("redis set error: %v", err)
-			}
+	sun := solar.CalculateSunTimes(day, req.Lat, req.Lon)
+	resp["solarNoon"] = sun.SolarNoon
+	switch {
+	case sun.PolarDay:
+		resp["polarDay"] = true
+	case sun.PolarNight:
+		resp["polarNight"] = true
+	default:
+		resp["sunrise"] = sun.Sunrise
+		resp["sunset"] = sun.Sunset
+	}
+	return resp, nil
+}
+
+func (h *BaseHandler) estimateHandler(w http.ResponseWriter, r *http.Request) {
+	var req estimateReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad json", http.StatusBadRequest)
+		return
+	}
+
+	site, err := h.resolveSite(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	nowLocal := time.Now().In(site.loc)
+	day := startOfDay(nowLocal)
+
+	daySite, known := h.cachedSnowCover(r.Context(), req, site, day)
+	if known {
+		if blob, ok := h.cacheGet(r.Context(), daySite.cacheKey(req, day)); ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Cache", "HIT")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(blob)
+			return
 		}
 	}
 
+	members, err := site.fetchForecast(r.Context(), req, day, day)
+	if err != nil {
+		http.Error(w, "weather fetch failed", http.StatusBadGateway)
+		return
+	}
+	if !known {
+		daySite = site.withSnowCover(req, h.snowHistory(r.Context(), req, site, day, members[0]), day)
+		h.cacheSnowCover(r.Context(), req, daySite, day, nowLocal)
+	}
+
+	resp, err := buildDayEstimate(req, daySite, day, members)
+	if err != nil {
+		http.Error(w, "calc failed", http.StatusInternalServerError)
+		return
+	}
+
+	if blob, err := json.Marshal(resp); err == nil {
+		h.cacheSet(r.Context(), daySite.cacheKey(req, day), blob, estimateTTL(resp, day, nowLocal))
+	}
+
 	w.Header().Set("X-Cache", "MISS")
 	writeJSON(w, http.StatusOK, resp)
 }
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+	"github.com/joseph-gunnarsson/solar-cast/internals/solar"
+)
+
+const (
+	maxHistoricalDays = 3 * 366
+	historicalTTL     = 7 * 24 * time.Hour
+)
+
+type historicalReq struct {
+	estimateReq
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// historicalHandler replays archived weather through the solar model for a
+// past date range and returns daily and monthly totals.
+func (h *BaseHandler) historicalHandler(w http.ResponseWriter, r *http.Request) {
+	var req historicalReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad json", http.StatusBadRequest)
+		return
+	}
+
+	site, err := h.resolveSite(req.estimateReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", req.Start, site.loc)
+	if err != nil {
+		http.Error(w, "bad start date", http.StatusBadRequest)
+		return
+	}
+	end, err := time.ParseInLocation("2006-01-02", req.End, site.loc)
+	if err != nil {
+		http.Error(w, "bad end date", http.StatusBadRequest)
+		return
+	}
+	today := startOfDay(time.Now().In(site.loc))
+	if end.Before(start) || !end.Before(today) {
+		http.Error(w, "date range must be in the past with end >= start", http.StatusBadRequest)
+		return
+	}
+	if end.Sub(start) > maxHistoricalDays*24*time.Hour {
+		http.Error(w, fmt.Sprintf("date range exceeds %d days", maxHistoricalDays), http.StatusBadRequest)
+		return
+	}
+
+	cacheKey := fmt.Sprintf(
+		"historical:%s:%s:%s:%s:%.6f:%.6f:%s",
+		req.Start, req.End, site.tz, req.Panel, req.Lat, req.Lon, site.optionsHash(),
+	)
+	if blob, ok := h.cacheGet(r.Context(), cacheKey); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Cache", "HIT")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(blob)
+		return
+	}
+
+	wp, err := clients.FetchArchiveWeather(r.Context(), req.Lat, req.Lon, start, end, site.tz)
+	if err != nil {
+		http.Error(w, "weather fetch failed", http.StatusBadGateway)
+		return
+	}
+
+	res, err := site.calculate(req.estimateReq, wp)
+	if err != nil {
+		http.Error(w, "calc failed", http.StatusInternalServerError)
+		return
+	}
+	// The range runs as one continuous period, so battery charge carries
+	// over midnight.
+	grid := site.applyGrid(res)
+	totalBase, totalLow, totalHigh := solar.Totals(res.points)
+
+	resp := map[string]any{
+		"panel":       req.Panel,
+		"lat":         req.Lat,
+		"lon":         req.Lon,
+		"timezone":    wp.Timezone,
+		"start":       req.Start,
+		"end":         req.End,
+		"totalWh":     totalBase,
+		"totalLowWh":  totalLow,
+		"totalHighWh": totalHigh,
+		"bands":       res.bands,
+		"daily":       solar.AggregateDaily(res.points),
+		"monthly":     solar.AggregateMonthly(res.points),
+	}
+	if res.system != nil {
+		resp["system"] = res.system
+	}
+	grid.addTo(resp)
+
+	if blob, err := json.Marshal(resp); err == nil {
+		h.cacheSet(r.Context(), cacheKey, blob, historicalTTL)
+	}
+
+	w.Header().Set("X-Cache", "MISS")
+	writeJSON(w, http.StatusOK, resp)
+}
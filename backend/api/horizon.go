@@ -0,0 +1,21 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/solar"
+)
+
+const maxHorizonUpload = 1 << 20
+
+// horizonImportHandler converts an uploaded PVGIS horizon file into the
+// azimuth/elevation list that estimate requests accept as "horizon".
+func (h *BaseHandler) horizonImportHandler(w http.ResponseWriter, r *http.Request) {
+	horizon, err := solar.ParseHorizon(io.LimitReader(r.Body, maxHorizonUpload))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"horizon": horizon})
+}
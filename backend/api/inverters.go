@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/solar"
+)
+
+func (h *BaseHandler) inverterAutoCompleteHandler(rw http.ResponseWriter, r *http.Request) {
+	response := []string{}
+	data := h.getInverters()
+	query := r.PathValue("inverter")
+	if query == "" {
+		http.Error(rw, "Query parameter 'inverter' is required", http.StatusBadRequest)
+		return
+	}
+	for name := range data {
+		if strings.Contains(strings.ToLower(name), strings.ToLower(query)) {
+			response = append(response, name)
+			if len(response) >= 5 {
+				break
+			}
+		}
+	}
+	writeJSON(rw, http.StatusOK, response)
+}
+
+func (h *BaseHandler) getInverter(rw http.ResponseWriter, r *http.Request) {
+	query := r.PathValue("inverter")
+	inverter, exists := h.getInverters()[query]
+	if !exists {
+		http.Error(rw, "Inverter not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(rw, http.StatusOK, inverter)
+}
+
+func (h *BaseHandler) swapInverters(newData map[string]solar.InverterData) {
+	h.inverterData.Store(newData)
+}
+
+func (h *BaseHandler) getInverters() map[string]solar.InverterData {
+	v := h.inverterData.Load()
+	if v == nil {
+		return nil
+	}
+	return v.(map[string]solar.InverterData)
+}
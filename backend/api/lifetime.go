@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+	"github.com/joseph-gunnarsson/solar-cast/internals/solar"
+)
+
+type lifetimeReq struct {
+	estimateReq
+	// AnnualKWh is the first-year yield; without it the previous calendar
+	// year of archived weather is modelled.
+	AnnualKWh *float64 `json:"annualKWh,omitempty"`
+	// Years defaults to the warranty term.
+	Years int `json:"years,omitempty"`
+}
+
+const maxLifetimeYears = 50
+
+// years resolves how many years to project, defaulting to the warranty term.
+func (req lifetimeReq) years(deg solar.Degradation) (int, error) {
+	years := req.Years
+	if years == 0 {
+		years = deg.Years
+	}
+	if years < 1 || years > maxLifetimeYears {
+		return 0, errors.New("years out of range")
+	}
+	return years, nil
+}
+
+// degradation is the panel's warranty curve, or for several sub-arrays their
+// curves weighted by rating.
+func (s estimateSite) degradation() solar.Degradation {
+	if len(s.subs) == 0 {
+		return s.panel.Degradation()
+	}
+	curves := make([]solar.Degradation, len(s.subs))
+	weights := make([]float64, len(s.subs))
+	for i, sub := range s.subs {
+		curves[i] = sub.Data.Degradation()
+		weights[i] = sub.Data.MaximumPowerPmax * float64(sub.Count)
+	}
+	return solar.BlendDegradation(curves, weights)
+}
+
+// baselineKWh models the last full calendar year at the site.
+func (h *BaseHandler) baselineKWh(ctx context.Context, req estimateReq, site estimateSite) (float64, int, error) {
+	year := time.Now().In(site.loc).Year() - 1
+	cacheKey := fmt.Sprintf(
+		"lifetime:%d:%s:%s:%.6f:%.6f:%s",
+		year, site.tz, req.Panel, req.Lat, req.Lon, site.optionsHash(),
+	)
+	if blob, ok := h.cacheGet(ctx, cacheKey); ok {
+		var kwh float64
+		if err := json.Unmarshal(blob, &kwh); err == nil {
+			return kwh, year, nil
+		}
+	}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, site.loc)
+	end := time.Date(year, time.December, 31, 0, 0, 0, 0, site.loc)
+	wp, err := clients.FetchArchiveWeather(ctx, req.Lat, req.Lon, start, end, site.tz)
+	if err != nil {
+		return 0, year, fmt.Errorf("weather fetch failed: %w", err)
+	}
+	res, err := site.calculate(req, wp)
+	if err != nil {
+		return 0, year, err
+	}
+	totalWh, _, _ := solar.Totals(res.points)
+	kwh := totalWh / 1000
+
+	if blob, err := json.Marshal(kwh); err == nil {
+		h.cacheSet(ctx, cacheKey, blob, historicalTTL)
+	}
+	return kwh, year, nil
+}
+
+// resolveBaseline returns the supplied first-year yield, or models one and
+// reports which calendar year it came from.
+func (h *BaseHandler) resolveBaseline(ctx context.Context, req lifetimeReq, site estimateSite) (float64, int, error) {
+	if req.AnnualKWh != nil {
+		return *req.AnnualKWh, 0, nil
+	}
+	return h.baselineKWh(ctx, req.estimateReq, site)
+}
+
+// lifetimeHandler projects yield year by year over the panel warranty,
+// starting from a supplied or modelled first-year baseline.
+func (h *BaseHandler) lifetimeHandler(w http.ResponseWriter, r *http.Request) {
+	var req lifetimeReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "bad json", http.StatusBadRequest)
+		return
+	}
+
+	site, err := h.resolveSite(req.estimateReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	deg := site.degradation()
+	years, err := req.years(deg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := map[string]any{
+		"panel":       req.Panel,
+		"lat":         req.Lat,
+		"lon":         req.Lon,
+		"degradation": deg,
+	}
+
+	baseline, year, err := h.resolveBaseline(r.Context(), req, site)
+	if err != nil {
+		http.Error(w, "baseline calc failed", http.StatusBadGateway)
+		return
+	}
+	if year != 0 {
+		resp["baselineYear"] = year
+	}
+	resp["baselineKWh"] = baseline
+
+	projection, err := solar.ProjectLifetime(baseline, deg, years)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp["years"] = projection
+	resp["totalKWh"] = projection[len(projection)-1].CumulativeKWh
+
+	writeJSON(w, http.StatusOK, resp)
+}
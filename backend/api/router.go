@@ -9,16 +9,29 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-func Router(solarPanelData map[string]solar.SolarPanelData, redisClient *redis.Client) *http.ServeMux {
+func Router(
+	solarPanelData map[string]solar.SolarPanelData,
+	inverterData map[string]solar.InverterData,
+	redisClient *redis.Client,
+) *http.ServeMux {
 	mux := http.NewServeMux()
-	h := NewBaseHandler(solarPanelData, redisClient)
+	h := NewBaseHandler(solarPanelData, inverterData, redisClient)
 	adminToken := os.Getenv("ADMIN_TOKEN_SECRET")
 	mux.HandleFunc("GET /api/solar-panels/search/{panel}", h.solarPanelAutoCompleteHandler)
 	mux.HandleFunc("GET /api/solar-panels/{panel}", h.getSolarPanel)
 
+	mux.HandleFunc("GET /api/inverters/search/{inverter}", h.inverterAutoCompleteHandler)
+	mux.HandleFunc("GET /api/inverters/{inverter...}", h.getInverter)
+
 	mux.HandleFunc("GET /api/location/autocomplete", h.locationAutocompleteHandler)
 
 	mux.HandleFunc("POST /api/solar/estimate", h.estimateHandler)
+	mux.HandleFunc("POST /api/solar/forecast", h.forecastHandler)
+	mux.HandleFunc("POST /api/solar/historical", h.historicalHandler)
+	mux.HandleFunc("POST /api/solar/lifetime", h.lifetimeHandler)
+	mux.HandleFunc("POST /api/solar/finance", h.financeHandler)
+	mux.HandleFunc("POST /api/solar/ev-plan", h.evPlanHandler)
+	mux.HandleFunc("POST /api/horizon/import", h.horizonImportHandler)
 
 	mux.HandleFunc("GET /api/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -37,6 +50,11 @@ func Router(solarPanelData map[string]solar.SolarPanelData, redisClient *redis.C
 			return
 		}
 		h.swapData(newData) // atomic snapshot swap
+		if inverters, err := solar.LoadInverterData(); err == nil {
+			h.swapInverters(inverters)
+		} else {
+			log.Printf("inverter data not reloaded: %v", err)
+		}
 		log.Println("Solar panel data reloaded successfully.")
 		w.WriteHeader(http.StatusNoContent)
 	})
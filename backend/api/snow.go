@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+	"github.com/joseph-gunnarsson/solar-cast/internals/solar"
+)
+
+// snowLeadInDays of recent weather settle how much snow already lies on the
+// panels when a forecast starts.
+const snowLeadInDays = 14
+
+// snowProneTemp is the air temperature in °C at or below which snow may be
+// lying when the forecast carries no depth readings.
+const snowProneTemp = 3.0
+
+// snowProne reports whether snow may be lying when the forecast opens: the
+// first hour has snow on the ground or, without a depth reading, the first
+// day has snow falling or is cold enough to keep it.
+func snowProne(hours []clients.HourWeather) bool {
+	if len(hours) == 0 {
+		return false
+	}
+	if first := hours[0]; first.HasSnowDepth {
+		return solar.SnowOnGround(first)
+	}
+	for _, h := range hours[:min(len(hours), 24)] {
+		if h.Snowfall > 0 || h.AmbientTemp <= snowProneTemp {
+			return true
+		}
+	}
+	return false
+}
+
+// snowHistory joins recent weather to the control forecast so that every day
+// starts from the snow the days before it left on the panels. Recent weather
+// is only fetched when the forecast opens snow-prone, as the panels are
+// otherwise clear.
+func (h *BaseHandler) snowHistory(ctx context.Context, req estimateReq, site estimateSite, start time.Time, forecast clients.WeatherPack) clients.WeatherPack {
+	history := clients.WeatherPack{Timezone: forecast.Timezone, Elevation: forecast.Elevation}
+	if snowProne(forecast.Hours) {
+		history.Hours = append(history.Hours, h.snowLeadIn(ctx, req, site, start)...)
+	}
+	history.Hours = append(history.Hours, forecast.Hours...)
+	return history
+}
+
+// snowLeadIn fetches the weather of the days before start, cached until the
+// end of the site's day.
+func (h *BaseHandler) snowLeadIn(ctx context.Context, req estimateReq, site estimateSite, start time.Time) []clients.HourWeather {
+	cacheKey := fmt.Sprintf(
+		"snowleadin:%s:%s:%.6f:%.6f",
+		start.Format("2006-01-02"), site.tz, req.Lat, req.Lon,
+	)
+	if blob, ok := h.cacheGet(ctx, cacheKey); ok {
+		var hours []clients.HourWeather
+		if err := json.Unmarshal(blob, &hours); err == nil {
+			return hours
+		}
+	}
+
+	lead, err := clients.FetchHourlyWeatherRange(ctx, req.Lat, req.Lon,
+		start.AddDate(0, 0, -snowLeadInDays), start.AddDate(0, 0, -1), site.tz)
+	if err != nil {
+		log.Printf("snow lead-in fetch failed: %v", err)
+		return nil
+	}
+
+	if blob, err := json.Marshal(lead.Hours); err == nil {
+		now := time.Now().In(site.loc)
+		h.cacheSet(ctx, cacheKey, blob, dayTTL(startOfDay(now), now))
+	}
+	return lead.Hours
+}
+
+// snowCoverKey identifies the snow cover a site starts day under.
+func (s estimateSite) snowCoverKey(req estimateReq, day time.Time) string {
+	return fmt.Sprintf(
+		"snowcover:%s:%s:%.6f:%.6f:%s",
+		day.Format("2006-01-02"), s.tz, req.Lat, req.Lon, s.optionsHash(),
+	)
+}
+
+// cachedSnowCover returns the site under the snow cover an earlier request
+// worked out for day, so that every endpoint computes that day from the same
+// snow state.
+func (h *BaseHandler) cachedSnowCover(ctx context.Context, req estimateReq, site estimateSite, day time.Time) (estimateSite, bool) {
+	blob, ok := h.cacheGet(ctx, site.snowCoverKey(req, day))
+	if !ok {
+		return site, false
+	}
+	var cover []float64
+	if err := json.Unmarshal(blob, &cover); err != nil {
+		return site, false
+	}
+	return site.withCoverage(cover), true
+}
+
+// cacheSnowCover stores the snow cover site starts day under until the day
+// ends.
+func (h *BaseHandler) cacheSnowCover(ctx context.Context, req estimateReq, site estimateSite, day, now time.Time) {
+	if blob, err := json.Marshal(site.snowCoverage()); err == nil {
+		h.cacheSet(ctx, site.snowCoverKey(req, day), blob, dayTTL(day, now))
+	}
+}
+
+// snowCoverage lists the initial snow coverage of the array, or of each
+// sub-array in order.
+func (s estimateSite) snowCoverage() []float64 {
+	if len(s.subs) == 0 {
+		return []float64{s.arr.InitialSnowCoverage}
+	}
+	cover := make([]float64, len(s.subs))
+	for i, sub := range s.subs {
+		cover[i] = sub.Array.InitialSnowCoverage
+	}
+	return cover
+}
+
+// withCoverage returns the site with the given initial snow coverage, in the
+// order of snowCoverage. Coverage is rounded to whole percent, as it is part
+// of the cache key.
+func (s estimateSite) withCoverage(cover []float64) estimateSite {
+	round := func(c float64) float64 { return math.Round(c*100) / 100 }
+	if len(s.subs) == 0 {
+		if len(cover) > 0 {
+			s.arr.InitialSnowCoverage = round(cover[0])
+		}
+		return s
+	}
+	subs := make([]solar.SubArray, len(s.subs))
+	copy(subs, s.subs)
+	for i := range subs {
+		if i < len(cover) {
+			subs[i].Array.InitialSnowCoverage = round(cover[i])
+		}
+	}
+	s.subs = subs
+	return s
+}
+
+// withSnowCover returns the site with every array starting day under the
+// snow that history leaves on it.
+func (s estimateSite) withSnowCover(req estimateReq, history clients.WeatherPack, day time.Time) estimateSite {
+	if len(s.subs) == 0 {
+		return s.withCoverage([]float64{solar.SnowCoverageAt(history, req.Lat, req.Lon, s.arr, day)})
+	}
+	cover := make([]float64, len(s.subs))
+	for i, sub := range s.subs {
+		cover[i] = solar.SnowCoverageAt(history, req.Lat, req.Lon, sub.Array, day)
+	}
+	return s.withCoverage(cover)
+}
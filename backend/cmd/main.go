@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/joseph-gunnarsson/solar-cast/api"
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
 	"github.com/joseph-gunnarsson/solar-cast/internals/scraping"
 	"github.com/joseph-gunnarsson/solar-cast/internals/solar"
 	red "github.com/joseph-gunnarsson/solar-cast/redis"
@@ -18,6 +20,15 @@ func main() {
 	scrape := flag.Bool("scrape", false, "run web scraping to collect panel data")
 	serve := flag.Bool("serve", false, "start the HTTP server")
 	pages := flag.Int("pages", 1, "number of pages to scrape from ENF Solar listing")
+	importInverters := flag.String("import-inverters", "", "import a CEC inverter CSV into data/inverter_data.json")
+	tmy := flag.String("tmy", "", "simulate a year from an EPW or TMY3 CSV weather file")
+	panel := flag.String("panel", "Mono-Default-400", "panel model for -tmy")
+	tilt := flag.Float64("tilt", 0, "array tilt in degrees for -tmy")
+	azimuth := flag.Float64("azimuth", -1, "array azimuth in degrees for -tmy (default: equator-facing)")
+	horizon := flag.String("horizon", "", "PVGIS horizon file for -tmy")
+	load := flag.String("load", "", "load profile for -tmy: a synthetic shape (residential, daytime, flat) or a consumption CSV")
+	loadKWh := flag.Float64("load-kwh", solar.DefaultAnnualLoadKWh, "annual consumption in kWh for a synthetic -load")
+	battery := flag.Float64("battery", 0, "battery capacity in kWh to simulate with -load")
 	flag.Parse()
 
 	loadEnvIfLocal()
@@ -33,6 +44,23 @@ func main() {
 		log.Printf("scrape complete (%d models).", len(scraped))
 	}
 
+	if *importInverters != "" {
+		if err := runImportInverters(*importInverters); err != nil {
+			log.Fatalf("inverter import failed: %v", err)
+		}
+	}
+
+	if *tmy != "" {
+		opts := annualOptions{
+			panel: *panel, tilt: *tilt, azimuth: *azimuth, horizon: *horizon,
+			load: *load, loadKWh: *loadKWh, batteryKWh: *battery,
+		}
+		if err := runAnnual(*tmy, opts); err != nil {
+			log.Fatalf("annual simulation failed: %v", err)
+		}
+		return
+	}
+
 	if *serve {
 
 		data := scraped
@@ -42,13 +70,18 @@ func main() {
 				log.Fatalf("load panel data failed: %v", err)
 			}
 		}
-		if err := runServer(data); err != nil && err != http.ErrServerClosed {
+		inverters, err := solar.LoadInverterData()
+		if err != nil {
+			log.Printf("no inverter data loaded: %v", err)
+			inverters = map[string]solar.InverterData{}
+		}
+		if err := runServer(data, inverters); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("server error: %v", err)
 		}
 		return
 	}
 
-	if !*scrape && !*serve {
+	if !*scrape && !*serve && *importInverters == "" {
 		flag.Usage()
 	}
 }
@@ -74,10 +107,138 @@ func runScrape(pages int) (map[string]solar.SolarPanelData, error) {
 	return data, nil
 }
 
-func runServer(panelData map[string]solar.SolarPanelData) error {
+func runImportInverters(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := solar.ParseCECInverters(f)
+	if err != nil {
+		return err
+	}
+	if err := solar.SaveInverterDataToFile(data); err != nil {
+		return err
+	}
+	log.Printf("Saved %d inverters to data/inverter_data.json", len(data))
+	return nil
+}
+
+type annualOptions struct {
+	panel         string
+	tilt, azimuth float64
+	horizon       string
+	load          string
+	loadKWh       float64
+	batteryKWh    float64
+}
+
+func runAnnual(path string, opts annualOptions) error {
+	model, tilt, azimuth := opts.panel, opts.tilt, opts.azimuth
+	wf, err := clients.LoadWeatherFile(path)
+	if err != nil {
+		return err
+	}
+
+	panels := solar.DefaultPanelData()
+	if scraped, err := solar.LoadSolarPanelData(); err == nil {
+		for k, v := range scraped {
+			panels[k] = v
+		}
+	}
+	p, ok := panels[model]
+	if !ok {
+		return fmt.Errorf("unknown panel %q", model)
+	}
+
+	if azimuth < 0 {
+		azimuth = solar.DefaultAzimuth(wf.Lat)
+	}
+	arr := solar.Array{Tilt: tilt, Azimuth: azimuth, Albedo: solar.DefaultAlbedo}
+	if opts.horizon != "" {
+		f, err := os.Open(opts.horizon)
+		if err != nil {
+			return err
+		}
+		arr.Horizon, err = solar.ParseHorizon(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	y, err := solar.SimulateAnnualYield(p, wf.Weather, wf.Lat, wf.Lon, arr)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s (%.3f, %.3f) - %s, tilt %.0f°, azimuth %.0f°, %d hours\n",
+		wf.Name, wf.Lat, wf.Lon, p.ModelNo, arr.Tilt, arr.Azimuth, y.Hours)
+	for m, kwh := range y.MonthlyKWh {
+		fmt.Printf("  %-3s %8.2f kWh\n", time.Month(m + 1).String()[:3], kwh)
+	}
+	fmt.Printf("Annual:          %.1f kWh\n", y.AnnualKWh)
+	fmt.Printf("Specific yield:  %.0f kWh/kWp\n", y.SpecificYield)
+	fmt.Printf("Capacity factor: %.1f%%\n", y.CapacityFactor*100)
+	if len(arr.Horizon) > 0 {
+		fmt.Printf("Horizon loss:    %.1f kWh\n", y.ShadingLossKWh)
+	}
+	if y.BifacialGainKWh > 0 {
+		fmt.Printf("Bifacial gain:   %.1f kWh\n", y.BifacialGainKWh)
+	}
+	if y.SnowLossKWh > 0 {
+		fmt.Printf("Snow loss:       %.1f kWh\n", y.SnowLossKWh)
+	}
+
+	if opts.load == "" {
+		return nil
+	}
+	load, err := loadProfile(opts.load, opts.loadKWh)
+	if err != nil {
+		return err
+	}
+	points, _, _, _, err := solar.CalculateHourlyOutputForArray(p, wf.Weather, wf.Lat, wf.Lon, arr)
+	if err != nil {
+		return err
+	}
+	if opts.batteryKWh <= 0 {
+		s := solar.ApplyLoad(points, load)
+		printLoad("Without battery", s)
+		return nil
+	}
+	st, err := solar.SimulateAnnualStorage(points, load, solar.Battery{CapacityKWh: opts.batteryKWh})
+	if err != nil {
+		return err
+	}
+	printLoad("Without battery", st.WithoutBattery)
+	printLoad(fmt.Sprintf("With %.1f kWh battery", opts.batteryKWh), st.WithBattery)
+	fmt.Printf("  cycles %.0f, losses %.1f kWh\n", st.Battery.Cycles, st.Battery.LossWh/1000)
+	return nil
+}
+
+func loadProfile(spec string, annualKWh float64) (solar.LoadProfile, error) {
+	if shape := solar.LoadShape(spec); shape.Valid() {
+		return solar.SyntheticLoad(shape, annualKWh)
+	}
+	f, err := os.Open(spec)
+	if err != nil {
+		return solar.LoadProfile{}, err
+	}
+	defer f.Close()
+	return solar.ParseLoadCSV(f)
+}
+
+func printLoad(label string, s solar.LoadSummary) {
+	fmt.Printf("%s: load %.0f kWh, import %.0f kWh, export %.0f kWh, self-consumption %.0f%%, self-sufficiency %.0f%%\n",
+		label, s.LoadWh/1000, s.ImportWh/1000, s.ExportWh/1000, s.SelfConsumption*100, s.SelfSufficiency*100)
+}
+
+func runServer(panelData map[string]solar.SolarPanelData, inverterData map[string]solar.InverterData) error {
 	log.Printf("Loaded solar panel data for %d models.", len(panelData))
+	log.Printf("Loaded inverter data for %d models.", len(inverterData))
 	redisClient := red.GetRedisConnection()
-	mux := api.Router(panelData, redisClient)
+	mux := api.Router(panelData, inverterData, redisClient)
 	port := os.Getenv("backend_port")
 	if port == "" {
 		port = "8080"
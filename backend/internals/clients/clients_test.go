@@ -3,6 +3,9 @@ package clients
 import (
 	"context"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -26,3 +29,28 @@ func TestFetchHourlyWeather_Live(t *testing.T) {
 		)
 	}
 }
+
+func TestFetchHourlyWeatherRange_Snow(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Query().Get("hourly"), "snow_depth") {
+			t.Errorf("expected snow_depth to be requested, got %q", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"timezone":"Europe/Oslo","hourly":{"time":["2024-01-10T11:00","2024-01-10T12:00"],
+"temperature_2m":[-4,-3],"shortwave_radiation":[40,80],"snowfall":[1.4,0],"snow_depth":[0.31,null]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("OPEN_METEO_FORECAST_URL", srv.URL)
+
+	day := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	wp, err := FetchHourlyWeatherRange(context.Background(), 59.9, 10.6, day, day, "Europe/Oslo")
+	if err != nil {
+		t.Fatalf("FetchHourlyWeatherRange error: %v", err)
+	}
+	h := wp.Hours[0]
+	if !h.HasSnowDepth || h.Snowfall != 1.4 || h.SnowDepth != 31 {
+		t.Fatalf("expected snowfall in cm and depth converted to cm, got %+v", h)
+	}
+	if wp.Hours[1].HasSnowDepth {
+		t.Fatalf("expected a null depth to read as missing, got %+v", wp.Hours[1])
+	}
+}
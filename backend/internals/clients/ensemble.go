@@ -0,0 +1,126 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The GFS ensemble covers the full 16-day forecast horizon.
+const defaultEnsembleModel = "gfs_seamless"
+
+type ensembleAPIResponse struct {
+	Timezone  string                     `json:"timezone"`
+	Elevation float64                    `json:"elevation"`
+	Hourly    map[string]json.RawMessage `json:"hourly"`
+}
+
+// FetchEnsembleWeather returns one WeatherPack per ensemble member, control
+// run first. Members only carry GHI, temperature and wind, so the solar model
+// decomposes irradiance itself.
+func FetchEnsembleWeather(ctx context.Context, lat, lon float64, start, end time.Time, timezone string) ([]WeatherPack, error) {
+	q := weatherQuery(lat, lon, start, end, timezone)
+	q.Set("hourly", "temperature_2m,shortwave_radiation,wind_speed_10m,snowfall,snow_depth")
+	q.Set("models", envOr("OPEN_METEO_ENSEMBLE_MODEL", defaultEnsembleModel))
+
+	var apiResp ensembleAPIResponse
+	if err := getOpenMeteo(ctx, ensembleURL(), q, &apiResp); err != nil {
+		return nil, err
+	}
+	return parseEnsemble(apiResp)
+}
+
+func parseEnsemble(apiResp ensembleAPIResponse) ([]WeatherPack, error) {
+	var times []string
+	if err := json.Unmarshal(apiResp.Hourly["time"], &times); err != nil {
+		return nil, fmt.Errorf("open-meteo ensemble: time: %w", err)
+	}
+	n := len(times)
+	if n == 0 {
+		return nil, errors.New("open-meteo ensemble: empty hourly data")
+	}
+
+	stamps := make([]time.Time, n)
+	for i, s := range times {
+		t, err := parseOMTime(s, apiResp.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		stamps[i] = t
+	}
+
+	// Member series are keyed "<var>" for the control run and
+	// "<var>_memberNN" for the perturbed runs.
+	var suffixes []string
+	for key := range apiResp.Hourly {
+		if s, ok := strings.CutPrefix(key, "shortwave_radiation"); ok {
+			suffixes = append(suffixes, s)
+		}
+	}
+	sort.Strings(suffixes)
+
+	series := func(key string) ([]float64, error) {
+		var v []float64
+		raw, ok := apiResp.Hourly[key]
+		if !ok {
+			return nil, fmt.Errorf("open-meteo ensemble: missing %s", key)
+		}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, fmt.Errorf("open-meteo ensemble: %s: %w", key, err)
+		}
+		if len(v) != n {
+			return nil, fmt.Errorf("open-meteo ensemble: %s has %d values, want %d", key, len(v), n)
+		}
+		return v, nil
+	}
+
+	members := make([]WeatherPack, 0, len(suffixes))
+	for _, s := range suffixes {
+		ghi, err := series("shortwave_radiation" + s)
+		if err != nil {
+			return nil, err
+		}
+		temp, err := series("temperature_2m" + s)
+		if err != nil {
+			return nil, err
+		}
+
+		// Wind and snow are optional; without them the temperature models
+		// see calm air and the panels stay clear. Snow depth may hold nulls,
+		// which count as missing readings.
+		wind, _ := series("wind_speed_10m" + s)
+		snowfall, _ := series("snowfall" + s)
+		var depth []*float64
+		if raw, ok := apiResp.Hourly["snow_depth"+s]; ok {
+			if err := json.Unmarshal(raw, &depth); err != nil || len(depth) != n {
+				depth = nil
+			}
+		}
+
+		hours := make([]HourWeather, n)
+		for i := range hours {
+			hours[i] = HourWeather{Time: stamps[i], AmbientTemp: temp[i], IrradianceGHI: ghi[i]}
+			if wind != nil {
+				hours[i].WindSpeed = wind[i]
+			}
+			if snowfall != nil {
+				hours[i].Snowfall = snowfall[i]
+			}
+			if depth != nil && depth[i] != nil {
+				// Open-Meteo reports depth in metres.
+				hours[i].SnowDepth = *depth[i] * 100
+				hours[i].HasSnowDepth = true
+			}
+		}
+		members = append(members, WeatherPack{Timezone: apiResp.Timezone, Hours: hours, Elevation: apiResp.Elevation})
+	}
+
+	if len(members) == 0 {
+		return nil, errors.New("open-meteo ensemble: no members")
+	}
+	return members, nil
+}
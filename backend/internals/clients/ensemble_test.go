@@ -0,0 +1,73 @@
+package clients
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const sampleEnsemble = `{"timezone":"Europe/Oslo","elevation":94,"hourly":{
+"time":["2024-06-01T12:00","2024-06-01T13:00"],
+"temperature_2m":[18,19],"shortwave_radiation":[600,650],
+"temperature_2m_member01":[17,18],"shortwave_radiation_member01":[400,420],
+"temperature_2m_member02":[19,20],"shortwave_radiation_member02":[700,720],
+"wind_speed_10m":[3,4],"wind_speed_10m_member01":[2,2],"wind_speed_10m_member02":[5,6],
+"snow_depth":[0.12,0.1],"snow_depth_member01":[null,0]}}`
+
+func TestFetchEnsembleWeather(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("models") == "" {
+			t.Errorf("expected models parameter, got %q", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(sampleEnsemble))
+	}))
+	defer srv.Close()
+	t.Setenv("OPEN_METEO_ENSEMBLE_URL", srv.URL)
+
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	members, err := FetchEnsembleWeather(context.Background(), 59.9, 10.6, day, day, "Europe/Oslo")
+	if err != nil {
+		t.Fatalf("FetchEnsembleWeather error: %v", err)
+	}
+	if len(members) != 3 {
+		t.Fatalf("expected 3 members, got %d", len(members))
+	}
+	if got := members[0].Hours[0].IrradianceGHI; got != 600 {
+		t.Fatalf("expected control run first, got GHI %v", got)
+	}
+	if got := members[2].Hours[1]; got.IrradianceGHI != 720 || got.AmbientTemp != 20 || got.WindSpeed != 6 {
+		t.Fatalf("unexpected member02 hour: %+v", got)
+	}
+	if members[2].Elevation != 94 {
+		t.Fatalf("expected every member to carry the site elevation, got %v", members[2].Elevation)
+	}
+	if members[1].Hours[0].Time.Hour() != 12 {
+		t.Fatalf("unexpected time: %v", members[1].Hours[0].Time)
+	}
+	if got := members[0].Hours[0]; !got.HasSnowDepth || math.Abs(got.SnowDepth-12) > 1e-9 {
+		t.Fatalf("expected 12 cm of snow in the control run, got %+v", got)
+	}
+	if m := members[1].Hours; m[0].HasSnowDepth || !m[1].HasSnowDepth || m[1].SnowDepth != 0 {
+		t.Fatalf("expected null depth to read as missing and 0 as bare ground: %+v", m)
+	}
+	if members[2].Hours[0].HasSnowDepth {
+		t.Fatal("expected no depth for a member without the series")
+	}
+}
+
+func TestFetchEnsembleWeather_LengthMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"timezone":"UTC","hourly":{"time":["2024-06-01T12:00"],
+"temperature_2m":[18,19],"shortwave_radiation":[600]}}`))
+	}))
+	defer srv.Close()
+	t.Setenv("OPEN_METEO_ENSEMBLE_URL", srv.URL)
+
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := FetchEnsembleWeather(context.Background(), 0, 0, day, day, "UTC"); err == nil {
+		t.Fatal("expected error for mismatched series length")
+	}
+}
@@ -0,0 +1,341 @@
+package clients
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TMYData is a typical-meteorological-year file reduced to the fields the
+// solar model needs. Hours are stamped at the end of each interval, matching
+// Open-Meteo's convention.
+type TMYData struct {
+	Name      string
+	Lat       float64
+	Lon       float64
+	Elevation float64
+	Weather   WeatherPack
+}
+
+// TMY files splice months from different years, so every record is mapped
+// onto one non-leap reference year.
+const tmyReferenceYear = 2023
+
+const epwSnowDepth = 30
+
+// EPW files mark missing values with these sentinels; anything at or above
+// them is treated as missing. NSRDB TMY3 files use tmy3Missing instead.
+const (
+	epwMissingTemp       = 99.9
+	epwMissingIrradiance = 9999
+	epwMissingWind       = 999
+	epwMissingSnowDepth  = 999
+	tmy3Missing          = -9900
+)
+
+func LoadWeatherFile(path string) (TMYData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return TMYData{}, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".epw":
+		return ParseEPW(f)
+	case ".csv":
+		return ParseTMY3(f)
+	default:
+		return TMYData{}, fmt.Errorf("unsupported weather file %q (want .epw or .csv)", path)
+	}
+}
+
+// ParseEPW reads an EnergyPlus weather file: eight header records followed by
+// hourly rows whose hour field runs 1-24.
+func ParseEPW(r io.Reader) (TMYData, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return TMYData{}, fmt.Errorf("epw: %w", err)
+	}
+	if len(header) < 10 || header[0] != "LOCATION" {
+		return TMYData{}, errors.New("epw: missing LOCATION header")
+	}
+
+	m, err := parseFloats(header, 6, 7, 8, 9)
+	if err != nil {
+		return TMYData{}, fmt.Errorf("epw: LOCATION: %w", err)
+	}
+	out := TMYData{Name: header[1], Lat: m[0], Lon: m[1], Elevation: m[3]}
+	loc := fixedZone(m[2])
+	out.Weather.Timezone = loc.String()
+	out.Weather.Elevation = out.Elevation
+
+	for i := 0; i < 7; i++ {
+		if _, err := cr.Read(); err != nil {
+			return TMYData{}, fmt.Errorf("epw: header: %w", err)
+		}
+	}
+
+	var gaps []hourGaps
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return TMYData{}, fmt.Errorf("epw: %w", err)
+		}
+		if len(rec) < 22 {
+			return TMYData{}, fmt.Errorf("epw: short record %q", strings.Join(rec, ","))
+		}
+
+		v, err := parseFloats(rec, 1, 2, 3, 6, 13, 14, 15, 21)
+		if err != nil {
+			return TMYData{}, fmt.Errorf("epw: %w", err)
+		}
+		month, day, hour := int(v[0]), int(v[1]), int(v[2])
+		if month == 2 && day == 29 {
+			continue
+		}
+
+		hw := HourWeather{
+			Time:          tmyTime(month, day, hour, loc),
+			AmbientTemp:   v[3],
+			IrradianceGHI: v[4],
+			IrradianceDNI: v[5],
+			IrradianceDHI: v[6],
+			HasComponents: true,
+			WindSpeed:     v[7],
+		}
+		// Snow depth in cm.
+		if len(rec) > epwSnowDepth {
+			if depth, err := parseFloatField(rec[epwSnowDepth]); err == nil && depth < epwMissingSnowDepth {
+				hw.SnowDepth, hw.HasSnowDepth = depth, true
+			}
+		}
+		var g hourGaps
+		g.mark(gapTemp, v[3] >= epwMissingTemp)
+		g.mark(gapGHI, v[4] >= epwMissingIrradiance)
+		g.mark(gapDNI, v[5] >= epwMissingIrradiance)
+		g.mark(gapDHI, v[6] >= epwMissingIrradiance)
+		g.mark(gapWind, v[7] >= epwMissingWind)
+		out.Weather.Hours = append(out.Weather.Hours, hw)
+		gaps = append(gaps, g)
+	}
+
+	if len(out.Weather.Hours) == 0 {
+		return TMYData{}, errors.New("epw: no hourly data")
+	}
+	if err := fillGaps(out.Weather.Hours, gaps); err != nil {
+		return TMYData{}, fmt.Errorf("epw: %w", err)
+	}
+	return out, nil
+}
+
+// ParseTMY3 reads the NSRDB TMY3 CSV layout: a metadata line
+// (USAF,Name,State,TZ,lat,lon,elev), a column header line, then hourly rows.
+func ParseTMY3(r io.Reader) (TMYData, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	meta, err := cr.Read()
+	if err != nil {
+		return TMYData{}, fmt.Errorf("tmy3: %w", err)
+	}
+	if len(meta) < 7 {
+		return TMYData{}, errors.New("tmy3: short metadata line")
+	}
+	m, err := parseFloats(meta, 3, 4, 5, 6)
+	if err != nil {
+		return TMYData{}, fmt.Errorf("tmy3: metadata: %w", err)
+	}
+	out := TMYData{Name: meta[1], Lat: m[1], Lon: m[2], Elevation: m[3]}
+	loc := fixedZone(m[0])
+	out.Weather.Timezone = loc.String()
+	out.Weather.Elevation = out.Elevation
+
+	cols, err := cr.Read()
+	if err != nil {
+		return TMYData{}, fmt.Errorf("tmy3: %w", err)
+	}
+	idx := map[string]int{}
+	for i, c := range cols {
+		idx[strings.TrimSpace(c)] = i
+	}
+	need := []string{"Date (MM/DD/YYYY)", "Time (HH:MM)", "GHI (W/m^2)", "DNI (W/m^2)", "DHI (W/m^2)", "Dry-bulb (C)"}
+	for _, n := range need {
+		if _, ok := idx[n]; !ok {
+			return TMYData{}, fmt.Errorf("tmy3: missing column %q", n)
+		}
+	}
+
+	var gaps []hourGaps
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return TMYData{}, fmt.Errorf("tmy3: %w", err)
+		}
+		if len(rec) < len(cols) {
+			return TMYData{}, fmt.Errorf("tmy3: short record %q", strings.Join(rec, ","))
+		}
+
+		var month, day, year, hour, minute int
+		if _, err := fmt.Sscanf(rec[idx["Date (MM/DD/YYYY)"]], "%d/%d/%d", &month, &day, &year); err != nil {
+			return TMYData{}, fmt.Errorf("tmy3: date %q: %w", rec[idx["Date (MM/DD/YYYY)"]], err)
+		}
+		if _, err := fmt.Sscanf(rec[idx["Time (HH:MM)"]], "%d:%d", &hour, &minute); err != nil {
+			return TMYData{}, fmt.Errorf("tmy3: time %q: %w", rec[idx["Time (HH:MM)"]], err)
+		}
+		if month == 2 && day == 29 {
+			continue
+		}
+
+		v, err := parseFloats(rec, idx["Dry-bulb (C)"], idx["GHI (W/m^2)"], idx["DNI (W/m^2)"], idx["DHI (W/m^2)"])
+		if err != nil {
+			return TMYData{}, fmt.Errorf("tmy3: %w", err)
+		}
+
+		hw := HourWeather{
+			Time:          tmyTime(month, day, hour, loc),
+			AmbientTemp:   v[0],
+			IrradianceGHI: v[1],
+			IrradianceDNI: v[2],
+			IrradianceDHI: v[3],
+			HasComponents: true,
+		}
+		var g hourGaps
+		g.mark(gapTemp, v[0] <= tmy3Missing)
+		g.mark(gapGHI, v[1] <= tmy3Missing)
+		g.mark(gapDNI, v[2] <= tmy3Missing)
+		g.mark(gapDHI, v[3] <= tmy3Missing)
+		if i, ok := idx["Wspd (m/s)"]; ok {
+			if hw.WindSpeed, err = parseFloatField(rec[i]); err != nil {
+				return TMYData{}, fmt.Errorf("tmy3: wind: %w", err)
+			}
+			g.mark(gapWind, hw.WindSpeed <= tmy3Missing)
+		}
+		out.Weather.Hours = append(out.Weather.Hours, hw)
+		gaps = append(gaps, g)
+	}
+
+	if len(out.Weather.Hours) == 0 {
+		return TMYData{}, errors.New("tmy3: no hourly data")
+	}
+	if err := fillGaps(out.Weather.Hours, gaps); err != nil {
+		return TMYData{}, fmt.Errorf("tmy3: %w", err)
+	}
+	return out, nil
+}
+
+// hourGaps flags the fields of one record that held a missing-value sentinel.
+type hourGaps uint8
+
+const (
+	gapTemp hourGaps = 1 << iota
+	gapGHI
+	gapDNI
+	gapDHI
+	gapWind
+)
+
+func (g *hourGaps) mark(field hourGaps, missing bool) {
+	if missing {
+		*g |= field
+	}
+}
+
+var gapFields = []struct {
+	flag  hourGaps
+	name  string
+	value func(*HourWeather) *float64
+}{
+	{gapTemp, "dry bulb", func(h *HourWeather) *float64 { return &h.AmbientTemp }},
+	{gapGHI, "GHI", func(h *HourWeather) *float64 { return &h.IrradianceGHI }},
+	{gapDNI, "DNI", func(h *HourWeather) *float64 { return &h.IrradianceDNI }},
+	{gapDHI, "DHI", func(h *HourWeather) *float64 { return &h.IrradianceDHI }},
+	{gapWind, "wind", func(h *HourWeather) *float64 { return &h.WindSpeed }},
+}
+
+// fillGaps replaces missing values by linear interpolation between the
+// nearest valid hours of the same field, holding the first or last valid
+// value at the ends. A field with no valid hour at all is an error.
+func fillGaps(hours []HourWeather, gaps []hourGaps) error {
+	for _, f := range gapFields {
+		prev := -1
+		for i := 0; i <= len(hours); i++ {
+			if i < len(hours) && gaps[i]&f.flag != 0 {
+				continue
+			}
+			if i-prev > 1 {
+				if prev < 0 && i == len(hours) {
+					return fmt.Errorf("no valid %s values", f.name)
+				}
+				for j := prev + 1; j < i; j++ {
+					switch {
+					case prev < 0:
+						*f.value(&hours[j]) = *f.value(&hours[i])
+					case i == len(hours):
+						*f.value(&hours[j]) = *f.value(&hours[prev])
+					default:
+						a, b := *f.value(&hours[prev]), *f.value(&hours[i])
+						*f.value(&hours[j]) = a + (b-a)*float64(j-prev)/float64(i-prev)
+					}
+				}
+			}
+			prev = i
+		}
+	}
+	return nil
+}
+
+// tmyTime maps an hour-ending record (1-24) onto the reference year. Hour 24
+// of 31 December becomes midnight of the following year, which still lies
+// inside the last interval once the model steps back half an hour.
+func tmyTime(month, day, hour int, loc *time.Location) time.Time {
+	return time.Date(tmyReferenceYear, time.Month(month), day, 0, 0, 0, 0, loc).
+		Add(time.Duration(hour) * time.Hour)
+}
+
+func fixedZone(hours float64) *time.Location {
+	secs := int(hours * 3600)
+	sign := "+"
+	if secs < 0 {
+		sign = "-"
+	}
+	abs := secs
+	if abs < 0 {
+		abs = -abs
+	}
+	return time.FixedZone(fmt.Sprintf("UTC%s%02d:%02d", sign, abs/3600, abs%3600/60), secs)
+}
+
+func parseFloatField(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(s), 64)
+}
+
+func parseFloats(rec []string, idx ...int) ([]float64, error) {
+	out := make([]float64, len(idx))
+	for i, j := range idx {
+		if j >= len(rec) {
+			return nil, fmt.Errorf("missing field %d", j)
+		}
+		v, err := parseFloatField(rec[j])
+		if err != nil {
+			return nil, fmt.Errorf("field %d: %w", j, err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
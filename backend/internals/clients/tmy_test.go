@@ -0,0 +1,130 @@
+package clients
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleEPW = `LOCATION,Oslo Fornebu,-,NOR,IWEC Data,014880,59.90,10.62,1.0,10.0
+DESIGN CONDITIONS,0
+TYPICAL/EXTREME PERIODS,0
+GROUND TEMPERATURES,0
+HOLIDAYS/DAYLIGHT SAVINGS,No,0,0,0
+COMMENTS 1,sample
+COMMENTS 2,sample
+DATA PERIODS,1,1,Data,Sunday, 1/ 1,12/31
+1983,1,1,1,60,A7A7A7A7*0?9?9?9?9?9?9?9A7A7A7A7A7A7*0*0E8*0*0,-5.0,-7.0,85,99500,0,0,280,0,0,0,0,0,0,0,200,2.5,10,10,10.0,77777,9,999999999,0,0.0000,25,0,0.000,0.0,0.0
+1983,6,21,13,60,A7A7A7A7*0?9?9?9?9?9?9?9A7A7A7A7A7A7*0*0E8*0*0,18.0,10.0,60,101000,1200,1367,330,650,550,180,0,0,0,0,200,4.0,10,10,10.0,77777,9,999999999,0,0.0000,0,88,0.000,0.0,0.0
+1984,2,29,12,60,A7A7A7A7*0?9?9?9?9?9?9?9A7A7A7A7A7A7*0*0E8*0*0,0.0,-2.0,85,99500,0,0,280,100,0,100,0,0,0,0,200,2.5,10,10,10.0,77777,9,999999999,0,0.0000,0,88,0.000,0.0,0.0
+1983,12,31,24,60,A7A7A7A7*0?9?9?9?9?9?9?9A7A7A7A7A7A7*0*0E8*0*0,-3.0,-6.0,85,99500,0,0,280,0,0,0,0,0,0,0,200,2.5,10,10,10.0,77777,9,999999999,0,0.0000,999,88,0.000,0.0,0.0
+`
+
+const sampleTMY3 = `690150,"TWENTYNINE PALMS",CA,-8.0,34.300,-116.167,626
+Date (MM/DD/YYYY),Time (HH:MM),ETR (W/m^2),ETRN (W/m^2),GHI (W/m^2),GHI source,GHI uncert (%),DNI (W/m^2),DNI source,DNI uncert (%),DHI (W/m^2),DHI source,DHI uncert (%),Dry-bulb (C),Dry-bulb source,Dry-bulb uncert (%),Wspd (m/s),Wspd source,Wspd uncert (%)
+01/01/1976,01:00,0,0,0,1,0,0,1,0,0,1,0,10.0,A,7,2.1,A,7
+07/15/1991,12:00,1300,1360,980,1,8,850,1,15,120,1,8,38.5,A,7,3.6,A,7
+`
+
+func TestParseEPW(t *testing.T) {
+	d, err := ParseEPW(strings.NewReader(sampleEPW))
+	if err != nil {
+		t.Fatalf("ParseEPW error: %v", err)
+	}
+	if d.Name != "Oslo Fornebu" || d.Lat != 59.90 || d.Lon != 10.62 || d.Elevation != 10 {
+		t.Fatalf("unexpected location: %+v", d)
+	}
+	if len(d.Weather.Hours) != 3 {
+		t.Fatalf("expected leap day to be dropped, got %d hours", len(d.Weather.Hours))
+	}
+
+	h := d.Weather.Hours[1]
+	want := time.Date(tmyReferenceYear, time.June, 21, 13, 0, 0, 0, time.FixedZone("", 3600))
+	if !h.Time.Equal(want) {
+		t.Fatalf("got time %s, want %s", h.Time, want)
+	}
+	if h.AmbientTemp != 18 || h.IrradianceGHI != 650 || h.IrradianceDNI != 550 || h.IrradianceDHI != 180 || !h.HasComponents || h.WindSpeed != 4 {
+		t.Fatalf("unexpected hour: %+v", h)
+	}
+
+	if first := d.Weather.Hours[0]; first.SnowDepth != 25 || !first.HasSnowDepth {
+		t.Fatalf("expected 25 cm of snow on 1 January, got %+v", first)
+	}
+	if d.Weather.Hours[2].HasSnowDepth {
+		t.Fatal("expected 999 to read as missing snow depth")
+	}
+
+	last := d.Weather.Hours[2].Time
+	if last.Year() != tmyReferenceYear+1 || last.YearDay() != 1 || last.Hour() != 0 {
+		t.Fatalf("expected hour 24 to roll over to midnight, got %s", last)
+	}
+}
+
+// sampleEPWGaps has a middle hour with every sentinel the parser fills in.
+const sampleEPWGaps = `LOCATION,Oslo Fornebu,-,NOR,IWEC Data,014880,59.90,10.62,1.0,10.0
+DESIGN CONDITIONS,0
+TYPICAL/EXTREME PERIODS,0
+GROUND TEMPERATURES,0
+HOLIDAYS/DAYLIGHT SAVINGS,No,0,0,0
+COMMENTS 1,sample
+COMMENTS 2,sample
+DATA PERIODS,1,1,Data,Sunday, 1/ 1,12/31
+1983,6,21,12,60,A7A7A7A7*0?9?9?9?9?9?9?9A7A7A7A7A7A7*0*0E8*0*0,16.0,10.0,60,101000,1200,1367,330,600,500,160,0,0,0,0,200,3.0,10,10,10.0,77777,9,999999999,0,0.0000,0,88,0.000,0.0,0.0
+1983,6,21,13,60,A7A7A7A7*0?9?9?9?9?9?9?9A7A7A7A7A7A7*0*0E8*0*0,99.9,10.0,60,101000,1200,1367,330,9999,9999,9999,0,0,0,0,200,999,10,10,10.0,77777,9,999999999,0,0.0000,0,88,0.000,0.0,0.0
+1983,6,21,14,60,A7A7A7A7*0?9?9?9?9?9?9?9A7A7A7A7A7A7*0*0E8*0*0,18.0,10.0,60,101000,1200,1367,330,700,600,200,0,0,0,0,200,5.0,10,10,10.0,77777,9,999999999,0,0.0000,0,88,0.000,0.0,0.0
+`
+
+func TestParseEPW_MissingValues(t *testing.T) {
+	d, err := ParseEPW(strings.NewReader(sampleEPWGaps))
+	if err != nil {
+		t.Fatalf("ParseEPW error: %v", err)
+	}
+	h := d.Weather.Hours[1]
+	if h.AmbientTemp != 17 || h.IrradianceGHI != 650 || h.IrradianceDNI != 550 || h.IrradianceDHI != 180 || h.WindSpeed != 4 {
+		t.Fatalf("expected sentinels to be interpolated, got %+v", h)
+	}
+
+	allMissing := strings.ReplaceAll(sampleEPWGaps, ",600,500,160,", ",9999,500,160,")
+	allMissing = strings.ReplaceAll(allMissing, ",700,600,200,", ",9999,600,200,")
+	if _, err := ParseEPW(strings.NewReader(allMissing)); err == nil {
+		t.Fatal("expected an error when no hour has a valid GHI")
+	}
+}
+
+func TestParseTMY3(t *testing.T) {
+	d, err := ParseTMY3(strings.NewReader(sampleTMY3))
+	if err != nil {
+		t.Fatalf("ParseTMY3 error: %v", err)
+	}
+	if d.Name != "TWENTYNINE PALMS" || d.Lat != 34.3 || d.Lon != -116.167 {
+		t.Fatalf("unexpected location: %+v", d)
+	}
+	if len(d.Weather.Hours) != 2 {
+		t.Fatalf("expected 2 hours, got %d", len(d.Weather.Hours))
+	}
+	h := d.Weather.Hours[1]
+	if h.Time.Year() != tmyReferenceYear || h.Time.Month() != time.July || h.Time.Hour() != 12 {
+		t.Fatalf("unexpected time %s", h.Time)
+	}
+	if h.IrradianceGHI != 980 || h.IrradianceDNI != 850 || h.IrradianceDHI != 120 || h.AmbientTemp != 38.5 || h.WindSpeed != 3.6 {
+		t.Fatalf("unexpected hour: %+v", h)
+	}
+}
+
+func TestParseTMY3_MissingValues(t *testing.T) {
+	in := strings.Replace(sampleTMY3, ",0,1,0,10.0,A,7,", ",-9900,1,0,10.0,A,7,", 1)
+	d, err := ParseTMY3(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("ParseTMY3 error: %v", err)
+	}
+	if h := d.Weather.Hours[0]; h.IrradianceDHI != 120 {
+		t.Fatalf("expected the missing DHI to take the nearest valid value, got %+v", h)
+	}
+}
+
+func TestParseTMY3_MissingColumn(t *testing.T) {
+	in := "1,X,CA,-8,34,-116,0\nDate (MM/DD/YYYY),Time (HH:MM),GHI (W/m^2)\n"
+	if _, err := ParseTMY3(strings.NewReader(in)); err == nil {
+		t.Fatal("expected error for missing columns")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
 	"time"
 )
 
@@ -13,50 +14,108 @@ type HourWeather struct {
 	Time          time.Time
 	AmbientTemp   float64
 	IrradianceGHI float64
+	IrradianceDNI float64
+	IrradianceDHI float64
+	HasComponents bool
+	// WindSpeed is at 10 m, in m/s.
+	WindSpeed float64
+	// Snowfall is the hour's fresh snow and SnowDepth the snow on the
+	// ground, both in cm. HasSnowDepth tells a bare ground from a missing
+	// reading.
+	Snowfall     float64
+	SnowDepth    float64
+	HasSnowDepth bool
 }
 
 type WeatherPack struct {
 	Timezone string `json:"timezone"`
 	Hours    []HourWeather
+	// Elevation is the site's height above sea level in metres.
+	Elevation float64 `json:"elevation"`
 }
 
 type WeatherAPIResponse struct {
-	Timezone string `json:"timezone"`
-	Hourly   struct {
+	Timezone  string  `json:"timezone"`
+	Elevation float64 `json:"elevation"`
+	Hourly    struct {
 		Time               []string  `json:"time"`
 		Temperature2m      []float64 `json:"temperature_2m"`
 		ShortwaveRadiation []float64 `json:"shortwave_radiation"`
+		DirectNormal       []float64 `json:"direct_normal_irradiance"`
+		DiffuseRadiation   []float64 `json:"diffuse_radiation"`
+		WindSpeed10m       []float64 `json:"wind_speed_10m"`
+		Snowfall           []float64 `json:"snowfall"`
+
+		// SnowDepth keeps missing readings apart from bare ground.
+		SnowDepth []*float64 `json:"snow_depth"`
 	} `json:"hourly"`
 }
 
 func FetchHourlyWeather(ctx context.Context, lat, lon float64, day time.Time, timezone string) (WeatherPack, error) {
-	dayStr := day.Format("2006-01-02")
+	return FetchHourlyWeatherRange(ctx, lat, lon, day, day, timezone)
+}
+
+const (
+	defaultForecastURL = "https://api.open-meteo.com/v1/forecast"
+	defaultArchiveURL  = "https://archive-api.open-meteo.com/v1/archive"
+	defaultEnsembleURL = "https://ensemble-api.open-meteo.com/v1/ensemble"
+)
+
+// Base URLs can be overridden so a local stub can stand in for Open-Meteo.
+func forecastURL() string { return envOr("OPEN_METEO_FORECAST_URL", defaultForecastURL) }
+func archiveURL() string  { return envOr("OPEN_METEO_ARCHIVE_URL", defaultArchiveURL) }
+func ensembleURL() string { return envOr("OPEN_METEO_ENSEMBLE_URL", defaultEnsembleURL) }
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func FetchHourlyWeatherRange(ctx context.Context, lat, lon float64, start, end time.Time, timezone string) (WeatherPack, error) {
+	return fetchWeather(ctx, forecastURL(), weatherQuery(lat, lon, start, end, timezone))
+}
+
+func FetchArchiveWeather(ctx context.Context, lat, lon float64, start, end time.Time, timezone string) (WeatherPack, error) {
+	return fetchWeather(ctx, archiveURL(), weatherQuery(lat, lon, start, end, timezone))
+}
 
+func weatherQuery(lat, lon float64, start, end time.Time, timezone string) url.Values {
 	q := url.Values{}
 	q.Set("latitude", fmt.Sprintf("%.6f", lat))
 	q.Set("longitude", fmt.Sprintf("%.6f", lon))
-	q.Set("hourly", "temperature_2m,shortwave_radiation")
+	q.Set("hourly", "temperature_2m,shortwave_radiation,direct_normal_irradiance,diffuse_radiation,wind_speed_10m,snowfall,snow_depth")
+	q.Set("wind_speed_unit", "ms")
 	q.Set("timezone", timezone)
-	q.Set("start_date", dayStr)
-	q.Set("end_date", dayStr)
+	q.Set("start_date", start.Format("2006-01-02"))
+	q.Set("end_date", end.Format("2006-01-02"))
+	return q
+}
 
-	u := "https://api.open-meteo.com/v1/forecast?" + q.Encode()
+// getOpenMeteo issues a GET against an Open-Meteo endpoint and decodes the
+// JSON body into out.
+func getOpenMeteo(ctx context.Context, baseURL string, q url.Values, out any) error {
+	u := baseURL + "?" + q.Encode()
 
 	req, _ := http.NewRequestWithContext(ctx, "GET", u, nil)
 	req.Header.Set("User-Agent", "solar-cast/1.0 (+contact@example.com)")
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return WeatherPack{}, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return WeatherPack{}, fmt.Errorf("open-meteo: HTTP %d", resp.StatusCode)
+		return fmt.Errorf("open-meteo: HTTP %d", resp.StatusCode)
 	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
 
+func fetchWeather(ctx context.Context, baseURL string, q url.Values) (WeatherPack, error) {
 	var apiResp WeatherAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+	if err := getOpenMeteo(ctx, baseURL, q, &apiResp); err != nil {
 		return WeatherPack{}, err
 	}
 
@@ -65,25 +124,61 @@ func FetchHourlyWeather(ctx context.Context, lat, lon float64, day time.Time, ti
 		return WeatherPack{}, fmt.Errorf("open-meteo: empty hourly data")
 	}
 
+	hasComponents := len(apiResp.Hourly.DirectNormal) == n && len(apiResp.Hourly.DiffuseRadiation) == n
+	hasWind := len(apiResp.Hourly.WindSpeed10m) == n
+	hasSnowfall := len(apiResp.Hourly.Snowfall) == n
+	hasSnowDepth := len(apiResp.Hourly.SnowDepth) == n
+
 	hours := make([]HourWeather, 0, n)
 	for i := 0; i < n; i++ {
 		locTime, err := parseOMTime(apiResp.Hourly.Time[i], apiResp.Timezone)
 		if err != nil {
 			return WeatherPack{}, err
 		}
-		hours = append(hours, HourWeather{
+		hw := HourWeather{
 			Time:          locTime,
 			AmbientTemp:   apiResp.Hourly.Temperature2m[i],
 			IrradianceGHI: apiResp.Hourly.ShortwaveRadiation[i],
-		})
+		}
+		if hasComponents {
+			hw.IrradianceDNI = apiResp.Hourly.DirectNormal[i]
+			hw.IrradianceDHI = apiResp.Hourly.DiffuseRadiation[i]
+			hw.HasComponents = true
+		}
+		if hasWind {
+			hw.WindSpeed = apiResp.Hourly.WindSpeed10m[i]
+		}
+		if hasSnowfall {
+			hw.Snowfall = apiResp.Hourly.Snowfall[i]
+		}
+		if hasSnowDepth && apiResp.Hourly.SnowDepth[i] != nil {
+			// Open-Meteo reports depth in metres.
+			hw.SnowDepth = *apiResp.Hourly.SnowDepth[i] * 100
+			hw.HasSnowDepth = true
+		}
+		hours = append(hours, hw)
 	}
 
 	return WeatherPack{
-		Timezone: apiResp.Timezone,
-		Hours:    hours,
+		Timezone:  apiResp.Timezone,
+		Hours:     hours,
+		Elevation: apiResp.Elevation,
 	}, nil
 }
 
+// ForDay returns the hours that fall on day's calendar date in its location.
+func (wp WeatherPack) ForDay(day time.Time) WeatherPack {
+	y, m, d := day.Date()
+	out := WeatherPack{Timezone: wp.Timezone, Elevation: wp.Elevation}
+	for _, h := range wp.Hours {
+		hy, hm, hd := h.Time.In(day.Location()).Date()
+		if hy == y && hm == m && hd == d {
+			out.Hours = append(out.Hours, h)
+		}
+	}
+	return out
+}
+
 func parseOMTime(s, tz string) (time.Time, error) {
 
 	if t, err := time.Parse(time.RFC3339, s); err == nil {
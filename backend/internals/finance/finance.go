@@ -0,0 +1,139 @@
+package finance
+
+import (
+	"errors"
+	"math"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/solar"
+)
+
+// Inputs are in a single currency; prices are per kWh and rates are annual
+// fractions.
+type Inputs struct {
+	SystemCost       float64 `json:"systemCost"`
+	ElectricityPrice float64 `json:"electricityPrice"`
+	// Escalation raises the electricity price each year; the feed-in tariff
+	// is treated as fixed for the contract term.
+	Escalation   float64 `json:"escalation"`
+	FeedInTariff float64 `json:"feedInTariff"`
+	DiscountRate float64 `json:"discountRate"`
+	// SelfConsumption is the share of yield used on site and valued at the
+	// electricity price; the rest is exported at the feed-in tariff.
+	SelfConsumption float64 `json:"selfConsumption"`
+	AnnualOM        float64 `json:"annualOM,omitempty"`
+}
+
+type CashFlowYear struct {
+	Year       int     `json:"year"`
+	KWh        float64 `json:"kWh"`
+	Savings    float64 `json:"savings"`
+	CashFlow   float64 `json:"cashFlow"`
+	Cumulative float64 `json:"cumulative"`
+	Discounted float64 `json:"discounted"`
+}
+
+// Analysis leaves PaybackYears nil when the system does not pay for itself
+// within the projection, and IRR nil when no rate balances the cash flows.
+type Analysis struct {
+	PaybackYears *float64       `json:"paybackYears"`
+	NPV          float64        `json:"npv"`
+	IRR          *float64       `json:"irr"`
+	LCOE         float64        `json:"lcoe"`
+	Years        []CashFlowYear `json:"years"`
+}
+
+func (in Inputs) Validate() error {
+	switch {
+	case in.SystemCost <= 0:
+		return errors.New("system cost must be positive")
+	case in.ElectricityPrice < 0 || in.FeedInTariff < 0 || in.AnnualOM < 0:
+		return errors.New("prices must not be negative")
+	case in.Escalation <= -1 || in.DiscountRate <= -1:
+		return errors.New("rates must be above -100%")
+	case in.SelfConsumption < 0 || in.SelfConsumption > 1:
+		return errors.New("self-consumption must be between 0 and 1")
+	}
+	return nil
+}
+
+// Analyze values each projected year of yield and reduces the cash flows to
+// simple payback, NPV, IRR and LCOE. The system cost falls at year zero.
+func Analyze(in Inputs, lifetime []solar.LifetimeYear) (Analysis, error) {
+	if err := in.Validate(); err != nil {
+		return Analysis{}, err
+	}
+	if len(lifetime) == 0 {
+		return Analysis{}, errors.New("no yield to analyse")
+	}
+
+	flows := make([]float64, len(lifetime)+1)
+	flows[0] = -in.SystemCost
+
+	var a Analysis
+	cum := -in.SystemCost
+	discKWh, discOM := 0.0, 0.0
+	for i, y := range lifetime {
+		t := float64(i + 1)
+		price := in.ElectricityPrice * math.Pow(1+in.Escalation, t-1)
+		savings := y.KWh * (in.SelfConsumption*price + (1-in.SelfConsumption)*in.FeedInTariff)
+		cf := savings - in.AnnualOM
+		df := math.Pow(1+in.DiscountRate, t)
+
+		if a.PaybackYears == nil && cum+cf >= 0 && cf > 0 {
+			p := t - 1 + -cum/cf
+			a.PaybackYears = &p
+		}
+		cum += cf
+		flows[i+1] = cf
+		discKWh += y.KWh / df
+		discOM += in.AnnualOM / df
+
+		a.Years = append(a.Years, CashFlowYear{
+			Year:       i + 1,
+			KWh:        y.KWh,
+			Savings:    savings,
+			CashFlow:   cf,
+			Cumulative: cum,
+			Discounted: cf / df,
+		})
+	}
+
+	a.NPV = npv(flows, in.DiscountRate)
+	if irr, ok := irr(flows); ok {
+		a.IRR = &irr
+	}
+	if discKWh > 0 {
+		a.LCOE = (in.SystemCost + discOM) / discKWh
+	}
+	return a, nil
+}
+
+func npv(flows []float64, rate float64) float64 {
+	v := 0.0
+	for t, cf := range flows {
+		v += cf / math.Pow(1+rate, float64(t))
+	}
+	return v
+}
+
+// irr finds the rate where NPV crosses zero by bisection. Flows that start
+// negative and turn positive have at most one such rate.
+func irr(flows []float64) (float64, bool) {
+	lo, hi := -0.99, 1.0
+	fLo := npv(flows, lo)
+	for npv(flows, hi)*fLo > 0 {
+		if hi >= 1e3 {
+			return 0, false
+		}
+		hi *= 2
+	}
+	for i := 0; i < 200 && hi-lo > 1e-10; i++ {
+		mid := (lo + hi) / 2
+		if f := npv(flows, mid); f*fLo > 0 {
+			lo, fLo = mid, f
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2, true
+}
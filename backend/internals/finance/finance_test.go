@@ -0,0 +1,90 @@
+package finance
+
+import (
+	"math"
+	"testing"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/solar"
+)
+
+func flat(kwh float64, years int) []solar.LifetimeYear {
+	out := make([]solar.LifetimeYear, years)
+	for i := range out {
+		out[i] = solar.LifetimeYear{Year: i + 1, Factor: 1, KWh: kwh}
+	}
+	return out
+}
+
+func TestAnalyze(t *testing.T) {
+	in := Inputs{
+		SystemCost:       10000,
+		ElectricityPrice: 0.25,
+		FeedInTariff:     0.05,
+		DiscountRate:     0.05,
+		SelfConsumption:  0.5,
+	}
+	// 4000 kWh a year is worth 2000*0.25 + 2000*0.05 = 600.
+	a, err := Analyze(in, flat(4000, 25))
+	if err != nil {
+		t.Fatalf("Analyze error: %v", err)
+	}
+	if a.PaybackYears == nil || math.Abs(*a.PaybackYears-10000.0/600) > 1e-9 {
+		t.Fatalf("unexpected payback: %v", a.PaybackYears)
+	}
+
+	annuity := (1 - math.Pow(1.05, -25)) / 0.05
+	if math.Abs(a.NPV-(600*annuity-10000)) > 1e-6 {
+		t.Fatalf("unexpected NPV: %.2f", a.NPV)
+	}
+	if a.IRR == nil || math.Abs(npv(append([]float64{-10000}, repeat(600, 25)...), *a.IRR)) > 1e-4 {
+		t.Fatalf("IRR does not zero the NPV: %v", a.IRR)
+	}
+	if math.Abs(a.LCOE-10000/(4000*annuity)) > 1e-9 {
+		t.Fatalf("unexpected LCOE: %.4f", a.LCOE)
+	}
+	if a.Years[24].Cumulative != 600*25-10000 {
+		t.Fatalf("unexpected cumulative cash flow: %.2f", a.Years[24].Cumulative)
+	}
+}
+
+func TestAnalyze_Escalation(t *testing.T) {
+	in := Inputs{SystemCost: 5000, ElectricityPrice: 0.2, Escalation: 0.03, SelfConsumption: 1}
+	a, err := Analyze(in, flat(1000, 3))
+	if err != nil {
+		t.Fatalf("Analyze error: %v", err)
+	}
+	if math.Abs(a.Years[2].Savings-1000*0.2*1.03*1.03) > 1e-9 {
+		t.Fatalf("expected escalated price in year 3, got %.4f", a.Years[2].Savings)
+	}
+	if a.PaybackYears != nil {
+		t.Fatalf("expected no payback within 3 years, got %.2f", *a.PaybackYears)
+	}
+}
+
+func TestAnalyze_NoReturn(t *testing.T) {
+	in := Inputs{SystemCost: 5000, ElectricityPrice: 0.2, SelfConsumption: 1, AnnualOM: 500}
+	a, err := Analyze(in, flat(1000, 10))
+	if err != nil {
+		t.Fatalf("Analyze error: %v", err)
+	}
+	if a.PaybackYears != nil || a.IRR != nil {
+		t.Fatalf("expected no payback or IRR when O&M exceeds savings: %+v", a)
+	}
+}
+
+func TestInputsValidate(t *testing.T) {
+	if err := (Inputs{SystemCost: 1, SelfConsumption: 1.2}).Validate(); err == nil {
+		t.Fatal("expected error for self-consumption above one")
+	}
+	if err := (Inputs{}).Validate(); err == nil {
+		t.Fatal("expected error for zero system cost")
+	}
+}
+
+func repeat(v float64, n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = v
+	}
+	return out
+}
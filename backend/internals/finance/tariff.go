@@ -0,0 +1,148 @@
+package finance
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/solar"
+)
+
+type DayType string
+
+const (
+	AllDays  DayType = ""
+	Weekdays DayType = "weekday"
+	Weekends DayType = "weekend"
+)
+
+// TariffBand prices the hours from Start to End ("HH:MM", local time) on the
+// given days and months. End at or before Start wraps past midnight; leaving
+// both empty covers the whole day, and empty Months covers the year.
+type TariffBand struct {
+	Name   string       `json:"name,omitempty"`
+	Months []time.Month `json:"months,omitempty"`
+	Days   DayType      `json:"days,omitempty"`
+	Start  string       `json:"start,omitempty"`
+	End    string       `json:"end,omitempty"`
+	Import float64      `json:"import"`
+	Export float64      `json:"export"`
+}
+
+// Tariff is a time-of-use price list per kWh. The first matching band wins;
+// hours no band covers use the flat Import and Export rates.
+type Tariff struct {
+	Import float64      `json:"import"`
+	Export float64      `json:"export"`
+	Bands  []TariffBand `json:"bands,omitempty"`
+}
+
+const maxTariffBands = 48
+
+func (t Tariff) Validate() error {
+	if len(t.Bands) > maxTariffBands {
+		return fmt.Errorf("at most %d tariff bands", maxTariffBands)
+	}
+	for i, b := range t.Bands {
+		switch b.Days {
+		case AllDays, Weekdays, Weekends:
+		default:
+			return fmt.Errorf("band %d: unknown day type %q", i, b.Days)
+		}
+		for _, m := range b.Months {
+			if m < time.January || m > time.December {
+				return fmt.Errorf("band %d: month %d out of range", i, m)
+			}
+		}
+		if _, err := parseClock(b.Start); err != nil {
+			return fmt.Errorf("band %d: start: %w", i, err)
+		}
+		if _, err := parseClock(b.End); err != nil {
+			return fmt.Errorf("band %d: end: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Rates returns the import and export price in force at ts.
+func (t Tariff) Rates(ts time.Time) (imp, exp float64) {
+	for _, b := range t.Bands {
+		if b.covers(ts) {
+			return b.Import, b.Export
+		}
+	}
+	return t.Import, t.Export
+}
+
+func (b TariffBand) covers(ts time.Time) bool {
+	if len(b.Months) > 0 {
+		found := false
+		for _, m := range b.Months {
+			if m == ts.Month() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	weekend := ts.Weekday() == time.Saturday || ts.Weekday() == time.Sunday
+	if (b.Days == Weekdays && weekend) || (b.Days == Weekends && !weekend) {
+		return false
+	}
+
+	start, _ := parseClock(b.Start)
+	end, _ := parseClock(b.End)
+	m := ts.Hour()*60 + ts.Minute()
+	switch {
+	case start == end:
+		return true
+	case start < end:
+		return m >= start && m < end
+	default:
+		return m >= start || m < end
+	}
+}
+
+// parseClock converts "HH:MM" to minutes after midnight; "" is midnight and
+// "24:00" is allowed as an end time.
+func parseClock(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	var h, m int
+	if _, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil {
+		return 0, fmt.Errorf("bad time %q", s)
+	}
+	if h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("time %q out of range", s)
+	}
+	return (h*60 + m) % (24 * 60), nil
+}
+
+// PriceHours sets the Value of each point from the tariff in force at the
+// middle of its hour, with selfConsumption of the energy offsetting imports
+// and the rest exported. It returns the total value.
+func PriceHours(points []solar.HourlyPoint, t Tariff, selfConsumption float64) float64 {
+	total := 0.0
+	for i := range points {
+		imp, exp := t.Rates(points[i].Time.Add(-30 * time.Minute))
+		kwh := points[i].EnergyWh / 1000
+		points[i].Value = kwh * (selfConsumption*imp + (1-selfConsumption)*exp)
+		total += points[i].Value
+	}
+	return total
+}
+
+// PriceLoadHours values the flows ApplyLoad set on each point: self-consumed
+// energy saves the import rate and exports earn the export rate.
+func PriceLoadHours(points []solar.HourlyPoint, t Tariff) float64 {
+	total := 0.0
+	for i := range points {
+		imp, exp := t.Rates(points[i].Time.Add(-30 * time.Minute))
+		points[i].Value = (points[i].SelfConsumedWh*imp + points[i].ExportWh*exp) / 1000
+		total += points[i].Value
+	}
+	return total
+}
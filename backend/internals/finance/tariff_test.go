@@ -0,0 +1,120 @@
+package finance
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/solar"
+)
+
+func touTariff() Tariff {
+	return Tariff{
+		Import: 0.25,
+		Export: 0.05,
+		Bands: []TariffBand{
+			{Name: "night", Start: "23:00", End: "07:00", Import: 0.10, Export: 0.03},
+			{Name: "summer peak", Months: []time.Month{time.June, time.July, time.August}, Days: Weekdays, Start: "16:00", End: "19:00", Import: 0.40, Export: 0.15},
+		},
+	}
+}
+
+func TestTariffRates(t *testing.T) {
+	tariff := touTariff()
+	if err := tariff.Validate(); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2025, month, day, hour, 30, 0, 0, time.UTC)
+	}
+	cases := []struct {
+		ts       time.Time
+		imp, exp float64
+	}{
+		{at(time.June, 2, 2), 0.10, 0.03},     // night wraps midnight
+		{at(time.June, 2, 23), 0.10, 0.03},    // night starts
+		{at(time.June, 2, 17), 0.40, 0.15},    // Monday peak
+		{at(time.June, 7, 17), 0.25, 0.05},    // Saturday, no peak
+		{at(time.January, 6, 17), 0.25, 0.05}, // winter, no peak
+		{at(time.June, 2, 12), 0.25, 0.05},
+	}
+	for _, c := range cases {
+		imp, exp := tariff.Rates(c.ts)
+		if imp != c.imp || exp != c.exp {
+			t.Errorf("Rates(%s) = %.2f/%.2f, want %.2f/%.2f", c.ts, imp, exp, c.imp, c.exp)
+		}
+	}
+}
+
+func TestTariffValidate(t *testing.T) {
+	bad := []Tariff{
+		{Bands: []TariffBand{{Start: "25:00"}}},
+		{Bands: []TariffBand{{Start: "noon"}}},
+		{Bands: []TariffBand{{Days: "holiday"}}},
+		{Bands: []TariffBand{{Months: []time.Month{13}}}},
+	}
+	for i, tariff := range bad {
+		if err := tariff.Validate(); err == nil {
+			t.Errorf("case %d: expected validation error", i)
+		}
+	}
+	if err := (Tariff{Bands: []TariffBand{{Start: "00:00", End: "24:00"}}}).Validate(); err != nil {
+		t.Fatalf("expected 24:00 to be a valid end: %v", err)
+	}
+}
+
+func TestPriceHours(t *testing.T) {
+	points := []solar.HourlyPoint{
+		// Hour ending 17:00 is priced at 16:30, inside the peak.
+		{Time: time.Date(2025, time.June, 2, 17, 0, 0, 0, time.UTC), EnergyWh: 2000},
+		{Time: time.Date(2025, time.June, 2, 13, 0, 0, 0, time.UTC), EnergyWh: 4000},
+	}
+	total := PriceHours(points, touTariff(), 0.5)
+
+	peak := 2 * (0.5*0.40 + 0.5*0.15)
+	midday := 4 * (0.5*0.25 + 0.5*0.05)
+	if math.Abs(points[0].Value-peak) > 1e-9 || math.Abs(points[1].Value-midday) > 1e-9 {
+		t.Fatalf("unexpected values: %+v", points)
+	}
+	if math.Abs(total-(peak+midday)) > 1e-9 {
+		t.Fatalf("unexpected total: %.4f", total)
+	}
+}
+
+func TestPriceLoadHours(t *testing.T) {
+	points := []solar.HourlyPoint{{
+		Time:           time.Date(2025, time.June, 2, 13, 0, 0, 0, time.UTC),
+		EnergyWh:       3000,
+		SelfConsumedWh: 1000,
+		ExportWh:       2000,
+	}}
+	total := PriceLoadHours(points, touTariff())
+	if math.Abs(total-(1*0.25+2*0.05)) > 1e-9 || points[0].Value != total {
+		t.Fatalf("unexpected value: total %.4f point %.4f", total, points[0].Value)
+	}
+}
+
+func TestPriceLoadHours_BatteryAtDischargeRate(t *testing.T) {
+	// Midday surplus is stored and used in the evening peak.
+	points := []solar.HourlyPoint{
+		{Time: time.Date(2025, time.June, 2, 13, 0, 0, 0, time.UTC), EnergyWh: 2000},
+		{Time: time.Date(2025, time.June, 2, 17, 0, 0, 0, time.UTC), EnergyWh: 0},
+	}
+	var load solar.LoadProfile
+	load.Wh[time.June-1][0][12] = 1000
+	load.Wh[time.June-1][0][16] = 1000
+	solar.ApplyLoad(points, load)
+	solar.DispatchBattery(points, solar.Battery{CapacityKWh: 5, RoundTripEfficiency: 0.81})
+
+	total := PriceLoadHours(points, touTariff())
+	if math.Abs(points[0].Value-1*0.25) > 1e-9 {
+		t.Fatalf("expected only direct use priced at midday, got %.4f", points[0].Value)
+	}
+	if math.Abs(points[1].Value-0.81*0.40) > 1e-9 {
+		t.Fatalf("expected the discharge priced at the peak rate, got %.4f", points[1].Value)
+	}
+	if math.Abs(total-(points[0].Value+points[1].Value)) > 1e-9 {
+		t.Fatalf("unexpected total: %.4f", total)
+	}
+}
@@ -3,7 +3,7 @@ package scraping
 import (
 	"fmt"
 	"log"
-
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -110,6 +110,42 @@ func parseTempCoeff(coeffStr string) float64 {
 	return val / 100.0
 }
 
+var (
+	firstYearRe = regexp.MustCompile(`(?i)(?:first|1st)\s*year[^0-9%]*?([0-9]+(?:\.[0-9]+)?)\s*%`)
+	annualRe    = regexp.MustCompile(`(?i)([0-9]+(?:\.[0-9]+)?)\s*%\s*(?:/|per|each|every|a)\s*(?:year|yr|annum)`)
+	termRe      = regexp.MustCompile(`(?i)([0-9]+)\s*(?:years?|yrs?)`)
+)
+
+// parseDegradation reads warranty text such as "2% first year, 0.55%/year,
+// 25 years" into fractions and a term. Anything not found is left at zero.
+func parseDegradation(text string) (first, annual float64, years int) {
+	if m := firstYearRe.FindStringSubmatch(text); m != nil {
+		v, _ := strconv.ParseFloat(m[1], 64)
+		first = v / 100
+		text = strings.Replace(text, m[0], "", 1)
+	}
+	if m := annualRe.FindStringSubmatch(text); m != nil {
+		v, _ := strconv.ParseFloat(m[1], 64)
+		annual = v / 100
+	}
+	for _, m := range termRe.FindAllStringSubmatch(text, -1) {
+		if v, _ := strconv.Atoi(m[1]); v > years {
+			years = v
+		}
+	}
+	return first, annual, years
+}
+
+// parseBifaciality reads a datasheet value such as "70±5%" as a fraction.
+func parseBifaciality(text string) float64 {
+	text = strings.TrimSpace(strings.Split(text, "±")[0])
+	val, _ := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(text, "%")), 64)
+	if val > 1 {
+		val /= 100
+	}
+	return val
+}
+
 func GatherSolarPanelData(urls []string) map[string]solar.SolarPanelData {
 
 	solarPanelData := solar.SolarPanelData{}
@@ -152,6 +188,25 @@ func GatherSolarPanelData(urls []string) map[string]solar.SolarPanelData {
 			solarPanelData.MaximumPowerPmax = parseWatts(td)
 			fmt.Println("Maximum Power Pmax:", solarPanelData.MaximumPowerPmax)
 		}
+		if strings.Contains(th, "Warranty") || strings.Contains(th, "Degradation") {
+			td := strings.Trim(e.DOM.Find("td").First().Text(), " \n\r\t")
+
+			first, annual, years := parseDegradation(td)
+			if first > 0 {
+				solarPanelData.FirstYearDegradation = first
+			}
+			if annual > 0 {
+				solarPanelData.AnnualDegradation = annual
+			}
+			if years > solarPanelData.WarrantyYears {
+				solarPanelData.WarrantyYears = years
+			}
+		}
+		if strings.Contains(th, "Bifaciality") {
+			td := strings.Trim(e.DOM.Find("td").First().Text(), " \n\r\t")
+
+			solarPanelData.Bifaciality = parseBifaciality(td)
+		}
 
 	})
 
@@ -0,0 +1,42 @@
+package scraping
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseDegradation(t *testing.T) {
+	cases := []struct {
+		text   string
+		first  float64
+		annual float64
+		years  int
+	}{
+		{"First year 2%, then 0.55%/year, 25 years linear power warranty", 0.02, 0.0055, 25},
+		{"1st year degradation: 1% ; 0.4% per year for 30 yrs", 0.01, 0.004, 30},
+		{"12 Years product warranty", 0, 0, 12},
+		{"", 0, 0, 0},
+	}
+	for _, c := range cases {
+		first, annual, years := parseDegradation(c.text)
+		if math.Abs(first-c.first) > 1e-9 || math.Abs(annual-c.annual) > 1e-9 || years != c.years {
+			t.Errorf("parseDegradation(%q) = %v, %v, %d; want %v, %v, %d",
+				c.text, first, annual, years, c.first, c.annual, c.years)
+		}
+	}
+}
+
+func TestParseBifaciality(t *testing.T) {
+	cases := map[string]float64{
+		"70±5%":   0.7,
+		"80 %":    0.8,
+		"0.75":    0.75,
+		"":        0,
+		"unknown": 0,
+	}
+	for text, want := range cases {
+		if got := parseBifaciality(text); math.Abs(got-want) > 1e-9 {
+			t.Errorf("parseBifaciality(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package solar
+
+type PeriodTotal struct {
+	Period        string  `json:"period"`
+	EnergyWh      float64 `json:"energyWh"`
+	EnergyWhLow   float64 `json:"energyWhLow"`
+	EnergyWhHigh  float64 `json:"energyWhHigh"`
+	ClearSkyWh    float64 `json:"clearSkyWh"`
+	ShadingLossWh float64 `json:"shadingLossWh"`
+	CurtailedWh   float64 `json:"curtailedWh,omitempty"`
+	SnowLossWh    float64 `json:"snowLossWh,omitempty"`
+	PeakWh        float64 `json:"peakWh"`
+	Hours         int     `json:"hours"`
+}
+
+func AggregateDaily(points []HourlyPoint) []PeriodTotal {
+	return aggregateBy(points, "2006-01-02")
+}
+
+func AggregateMonthly(points []HourlyPoint) []PeriodTotal {
+	return aggregateBy(points, "2006-01")
+}
+
+// aggregateBy sums points into periods named by formatting each point's time
+// with layout. Points are assumed to be in chronological order.
+func aggregateBy(points []HourlyPoint, layout string) []PeriodTotal {
+	var out []PeriodTotal
+	for _, p := range points {
+		key := p.Time.Format(layout)
+		if len(out) == 0 || out[len(out)-1].Period != key {
+			out = append(out, PeriodTotal{Period: key})
+		}
+		cur := &out[len(out)-1]
+		cur.EnergyWh += p.EnergyWh
+		cur.EnergyWhLow += p.EnergyWhLow
+		cur.EnergyWhHigh += p.EnergyWhHigh
+		cur.ClearSkyWh += p.ClearSkyWh
+		cur.ShadingLossWh += p.ShadingLossWh
+		cur.CurtailedWh += p.CurtailedWh
+		cur.SnowLossWh += p.SnowLossWh
+		if p.EnergyWh > cur.PeakWh {
+			cur.PeakWh = p.EnergyWh
+		}
+		cur.Hours++
+	}
+	return out
+}
@@ -0,0 +1,35 @@
+package solar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAggregateDailyAndMonthly(t *testing.T) {
+	start := time.Date(2025, time.January, 31, 22, 0, 0, 0, time.UTC)
+	var points []HourlyPoint
+	for i := 0; i < 4; i++ {
+		points = append(points, HourlyPoint{
+			Time:         start.Add(time.Duration(i) * time.Hour),
+			EnergyWh:     float64(i + 1),
+			EnergyWhLow:  float64(i+1) * lowBuffer,
+			EnergyWhHigh: float64(i+1) * 1.1,
+		})
+	}
+
+	daily := AggregateDaily(points)
+	if len(daily) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(daily))
+	}
+	if daily[0].Period != "2025-01-31" || daily[1].Period != "2025-02-01" {
+		t.Fatalf("unexpected periods: %s, %s", daily[0].Period, daily[1].Period)
+	}
+	almostEqual(t, daily[0].EnergyWh, 3, 1e-9)
+	almostEqual(t, daily[1].EnergyWh, 7, 1e-9)
+	almostEqual(t, daily[1].PeakWh, 4, 1e-9)
+
+	monthly := AggregateMonthly(points)
+	if len(monthly) != 2 || monthly[1].Period != "2025-02" || monthly[1].Hours != 2 {
+		t.Fatalf("unexpected monthly aggregates: %+v", monthly)
+	}
+}
@@ -0,0 +1,81 @@
+package solar
+
+import (
+	"errors"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+type AnnualYield struct {
+	MonthlyKWh     [12]float64 `json:"monthlyKWh"`
+	AnnualKWh      float64     `json:"annualKWh"`
+	SpecificYield  float64     `json:"specificYield"`
+	CapacityFactor float64     `json:"capacityFactor"`
+	ShadingLossKWh float64     `json:"shadingLossKWh"`
+	Hours          int         `json:"hours"`
+	// BifacialGainKWh is the part of AnnualKWh produced by the rear face.
+	BifacialGainKWh float64 `json:"bifacialGainKWh,omitempty"`
+	SnowLossKWh     float64 `json:"snowLossKWh,omitempty"`
+}
+
+// SimulateAnnualYield runs a year of hourly weather (typically a TMY file)
+// through the array model. Hours are binned by the middle of their interval
+// so the hour-ending record at midnight on 1 January counts towards December.
+func SimulateAnnualYield(
+	panel SolarPanelData,
+	wp clients.WeatherPack,
+	lat, lon float64,
+	arr Array,
+) (AnnualYield, error) {
+	if panel.MaximumPowerPmax <= 0 {
+		return AnnualYield{}, errors.New("panel has no rated power")
+	}
+
+	points, total, _, _, err := CalculateHourlyOutputForArray(panel, wp, lat, lon, arr)
+	if err != nil {
+		return AnnualYield{}, err
+	}
+
+	var y AnnualYield
+	for _, p := range points {
+		m := p.Time.Add(-30*time.Minute).Month() - 1
+		y.MonthlyKWh[m] += p.EnergyWh / 1000
+		y.ShadingLossKWh += p.ShadingLossWh / 1000
+		y.BifacialGainKWh += p.BifacialGainWh / 1000
+		y.SnowLossKWh += p.SnowLossWh / 1000
+	}
+
+	kWp := panel.MaximumPowerPmax / 1000
+	y.Hours = len(points)
+	y.AnnualKWh = total / 1000
+	y.SpecificYield = y.AnnualKWh / kWp
+	if y.Hours > 0 {
+		y.CapacityFactor = y.AnnualKWh / (kWp * float64(y.Hours))
+	}
+	return y, nil
+}
+
+// AnnualStorage compares a year of grid exchange without and with a battery.
+type AnnualStorage struct {
+	WithoutBattery LoadSummary    `json:"withoutBattery"`
+	WithBattery    LoadSummary    `json:"withBattery"`
+	Battery        BatterySummary `json:"battery"`
+}
+
+// SimulateAnnualStorage nets a year of hourly output against the load and
+// then dispatches the battery over it in one continuous run, so charge
+// carries from day to day. The points are not modified.
+func SimulateAnnualStorage(points []HourlyPoint, load LoadProfile, b Battery) (AnnualStorage, error) {
+	if err := b.Validate(); err != nil {
+		return AnnualStorage{}, err
+	}
+	run := make([]HourlyPoint, len(points))
+	copy(run, points)
+
+	var a AnnualStorage
+	a.WithoutBattery = ApplyLoad(run, load)
+	a.Battery = DispatchBattery(run, b)
+	a.WithBattery = SummarizeLoad(run)
+	return a, nil
+}
@@ -0,0 +1,47 @@
+package solar
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+func TestSimulateAnnualYield_SyntheticYear(t *testing.T) {
+	loc := time.FixedZone("UTC+01:00", 3600)
+	var wp clients.WeatherPack
+	for d := 0; d < 365; d++ {
+		for h := 1; h <= 24; h++ {
+			ts := time.Date(2023, time.January, 1, 0, 0, 0, 0, loc).AddDate(0, 0, d).Add(time.Duration(h) * time.Hour)
+			ghi := math.Max(0, 700*math.Sin(math.Pi*float64(h-6)/12))
+			wp.Hours = append(wp.Hours, clients.HourWeather{Time: ts, AmbientTemp: 15, IrradianceGHI: ghi})
+		}
+	}
+	panel := SolarPanelData{MaximumPowerPmax: 400, TemperatureCoefficientPmax: -0.0035, NOCT_Temp: 45}
+
+	y, err := SimulateAnnualYield(panel, wp, 52, 10, Array{Tilt: 0, Azimuth: 180})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if y.Hours != 8760 {
+		t.Fatalf("expected 8760 hours, got %d", y.Hours)
+	}
+
+	var sum float64
+	for _, m := range y.MonthlyKWh {
+		if m <= 0 {
+			t.Fatalf("expected every month to produce, got %v", y.MonthlyKWh)
+		}
+		sum += m
+	}
+	almostEqual(t, sum, y.AnnualKWh, 1e-6)
+	almostEqual(t, y.SpecificYield, y.AnnualKWh/0.4, 1e-6)
+	almostEqual(t, y.CapacityFactor, y.AnnualKWh/(0.4*8760), 1e-9)
+}
+
+func TestSimulateAnnualYield_NoRating(t *testing.T) {
+	if _, err := SimulateAnnualYield(SolarPanelData{}, clients.WeatherPack{}, 0, 0, Array{}); err == nil {
+		t.Fatal("expected error for panel without rated power")
+	}
+}
@@ -0,0 +1,111 @@
+package solar
+
+import (
+	"errors"
+	"math"
+)
+
+// Battery is a home storage unit. Zero efficiency and power limits take
+// the defaults below; MinSoC and InitialSoC are fractions of capacity, and
+// the battery never starts below MinSoC.
+type Battery struct {
+	CapacityKWh         float64 `json:"capacityKWh"`
+	RoundTripEfficiency float64 `json:"roundTripEfficiency,omitempty"`
+	MaxChargeKW         float64 `json:"maxChargeKW,omitempty"`
+	MaxDischargeKW      float64 `json:"maxDischargeKW,omitempty"`
+	MinSoC              float64 `json:"minSoc,omitempty"`
+	InitialSoC          float64 `json:"initialSoc,omitempty"`
+}
+
+const (
+	DefaultRoundTripEfficiency = 0.9
+	// defaultCRate limits charge and discharge power to half the capacity
+	// per hour when no inverter rating is given.
+	defaultCRate = 0.5
+)
+
+type BatterySummary struct {
+	ChargedWh    float64 `json:"chargedWh"`
+	DischargedWh float64 `json:"dischargedWh"`
+	LossWh       float64 `json:"lossWh"`
+	StartSoC     float64 `json:"startSoc"`
+	EndSoC       float64 `json:"endSoc"`
+	// Cycles counts equivalent full cycles of the usable capacity.
+	Cycles float64 `json:"cycles"`
+}
+
+func (b Battery) Validate() error {
+	switch {
+	case b.CapacityKWh <= 0:
+		return errors.New("battery capacity must be positive")
+	case b.RoundTripEfficiency < 0 || b.RoundTripEfficiency > 1:
+		return errors.New("round-trip efficiency must be between 0 and 1")
+	case b.MaxChargeKW < 0 || b.MaxDischargeKW < 0:
+		return errors.New("battery power limits must not be negative")
+	case b.MinSoC < 0 || b.MinSoC >= 1:
+		return errors.New("minimum state of charge must be between 0 and 1")
+	case b.InitialSoC < 0 || b.InitialSoC > 1:
+		return errors.New("initial state of charge must be between 0 and 1")
+	}
+	return nil
+}
+
+func (b Battery) withDefaults() Battery {
+	if b.RoundTripEfficiency == 0 {
+		b.RoundTripEfficiency = DefaultRoundTripEfficiency
+	}
+	if b.MaxChargeKW == 0 {
+		b.MaxChargeKW = b.CapacityKWh * defaultCRate
+	}
+	if b.MaxDischargeKW == 0 {
+		b.MaxDischargeKW = b.CapacityKWh * defaultCRate
+	}
+	return b
+}
+
+// DispatchBattery runs a self-consumption strategy over points that already
+// carry a load (see ApplyLoad): surplus PV charges the battery before it is
+// exported, and the battery covers any deficit before the grid. Losses are
+// split evenly between charging and discharging. SelfConsumedWh becomes the
+// load met directly plus the battery's discharge, so stored energy counts in
+// the hour it is used rather than the hour it is charged.
+func DispatchBattery(points []HourlyPoint, b Battery) BatterySummary {
+	b = b.withDefaults()
+	capWh := b.CapacityKWh * 1000
+	minWh := b.MinSoC * capWh
+	eta := math.Sqrt(b.RoundTripEfficiency)
+
+	soc := math.Max(b.InitialSoC, b.MinSoC) * capWh
+	s := BatterySummary{StartSoC: soc / capWh}
+	for i := range points {
+		p := &points[i]
+		direct := math.Min(p.EnergyWh, p.LoadWh)
+		surplus := p.EnergyWh - direct
+		deficit := p.LoadWh - direct
+
+		charge := math.Min(surplus, math.Min(b.MaxChargeKW*1000, (capWh-soc)/eta))
+		charge = math.Max(charge, 0)
+		soc += charge * eta
+
+		discharge := math.Min(deficit, math.Min(b.MaxDischargeKW*1000, (soc-minWh)*eta))
+		discharge = math.Max(discharge, 0)
+		soc -= discharge / eta
+
+		p.BatteryChargeWh = charge
+		p.BatteryDischargeWh = discharge
+		level := soc / capWh
+		p.BatterySoC = &level
+		p.SelfConsumedWh = direct + discharge
+		p.ExportWh = surplus - charge
+		p.ImportWh = deficit - discharge
+
+		s.ChargedWh += charge
+		s.DischargedWh += discharge
+		s.LossWh += charge*(1-eta) + discharge*(1/eta-1)
+	}
+	s.EndSoC = soc / capWh
+	if usable := capWh - minWh; usable > 0 {
+		s.Cycles = s.DischargedWh / usable
+	}
+	return s
+}
@@ -0,0 +1,107 @@
+package solar
+
+import (
+	"testing"
+	"time"
+)
+
+func flatLoad(wh float64) LoadProfile {
+	var l LoadProfile
+	for m := range l.Wh {
+		for d := range l.Wh[m] {
+			for h := range l.Wh[m][d] {
+				l.Wh[m][d][h] = wh
+			}
+		}
+	}
+	return l
+}
+
+func TestDispatchBattery(t *testing.T) {
+	day := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	points := []HourlyPoint{
+		{Time: day.Add(12 * time.Hour), EnergyWh: 3000},
+		{Time: day.Add(13 * time.Hour), EnergyWh: 3000},
+		{Time: day.Add(20 * time.Hour), EnergyWh: 0},
+		{Time: day.Add(21 * time.Hour), EnergyWh: 0},
+	}
+	ApplyLoad(points, flatLoad(1000))
+
+	b := Battery{CapacityKWh: 3, RoundTripEfficiency: 0.81, MaxChargeKW: 2, MaxDischargeKW: 1, MinSoC: 0.1}
+	s := DispatchBattery(points, b)
+
+	// The first hour charges at the power limit, the second until full.
+	almostEqual(t, points[0].BatteryChargeWh, 2000, 1e-9)
+	almostEqual(t, points[0].ExportWh, 0, 1e-9)
+	almostEqual(t, *points[0].BatterySoC, 0.1+2000*0.9/3000, 1e-9)
+	almostEqual(t, *points[1].BatterySoC, 1, 1e-9)
+	almostEqual(t, points[1].ExportWh, 2000-points[1].BatteryChargeWh, 1e-9)
+
+	// Stored energy counts as self-consumed when it is used, not stored.
+	almostEqual(t, points[0].SelfConsumedWh, 1000, 1e-9)
+
+	// The evening is covered at the discharge limit.
+	almostEqual(t, points[2].BatteryDischargeWh, 1000, 1e-9)
+	almostEqual(t, points[2].SelfConsumedWh, 1000, 1e-9)
+	almostEqual(t, points[2].ImportWh, 0, 1e-9)
+	almostEqual(t, points[3].ImportWh, 0, 1e-9)
+
+	stored := (s.EndSoC - s.StartSoC) * 3000
+	almostEqual(t, s.ChargedWh-s.DischargedWh-s.LossWh, stored, 1e-6)
+
+	sum := SummarizeLoad(points)
+	almostEqual(t, sum.ImportWh, 0, 1e-9)
+	almostEqual(t, sum.SelfSufficiency, 1, 1e-9)
+}
+
+func TestDispatchBattery_MinSoC(t *testing.T) {
+	points := []HourlyPoint{{Time: time.Date(2025, time.June, 2, 22, 0, 0, 0, time.UTC)}}
+	ApplyLoad(points, flatLoad(800))
+
+	s := DispatchBattery(points, Battery{CapacityKWh: 5, MinSoC: 0.2, InitialSoC: 0.25, RoundTripEfficiency: 1})
+	almostEqual(t, points[0].BatteryDischargeWh, 250, 1e-9)
+	almostEqual(t, points[0].ImportWh, 550, 1e-9)
+	almostEqual(t, s.EndSoC, 0.2, 1e-9)
+
+	// An empty battery still reports its state of charge.
+	points = []HourlyPoint{{Time: time.Date(2025, time.June, 2, 22, 0, 0, 0, time.UTC)}}
+	ApplyLoad(points, flatLoad(800))
+	DispatchBattery(points, Battery{CapacityKWh: 5})
+	if points[0].BatterySoC == nil || *points[0].BatterySoC != 0 {
+		t.Fatalf("expected an empty battery to report 0, got %v", points[0].BatterySoC)
+	}
+}
+
+func TestBatteryValidate(t *testing.T) {
+	if err := (Battery{CapacityKWh: 10}).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, b := range []Battery{{}, {CapacityKWh: 5, MinSoC: 1}, {CapacityKWh: 5, RoundTripEfficiency: 1.2}} {
+		if err := b.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", b)
+		}
+	}
+}
+
+func TestSimulateAnnualStorage(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 1, 0, 0, 0, time.UTC)
+	points := make([]HourlyPoint, 0, 48)
+	for h := 0; h < 48; h++ {
+		e := 0.0
+		if hour := (h + 1) % 24; hour >= 10 && hour <= 15 {
+			e = 1500
+		}
+		points = append(points, HourlyPoint{Time: start.Add(time.Duration(h) * time.Hour), EnergyWh: e})
+	}
+
+	a, err := SimulateAnnualStorage(points, flatLoad(500), Battery{CapacityKWh: 10})
+	if err != nil {
+		t.Fatalf("SimulateAnnualStorage error: %v", err)
+	}
+	if a.WithBattery.ImportWh >= a.WithoutBattery.ImportWh || a.WithBattery.ExportWh >= a.WithoutBattery.ExportWh {
+		t.Fatalf("expected the battery to cut both import and export: %+v", a)
+	}
+	if points[0].LoadWh != 0 {
+		t.Fatal("expected the input points to be left untouched")
+	}
+}
@@ -0,0 +1,107 @@
+package solar
+
+import "math"
+
+const (
+	DefaultMountingHeight = 1.0
+	// bifacialModuleLength is the slope length in metres of a module mounted
+	// in portrait, used to place its edges above the ground.
+	bifacialModuleLength = 2.0
+	minEdgeClearance     = 0.1
+	// rearStructureLoss covers racking shade and mismatch on the rear face.
+	rearStructureLoss = 0.1
+)
+
+type RearIrradiance struct {
+	Beam   float64 `json:"beam"`
+	Sky    float64 `json:"sky"`
+	Ground float64 `json:"ground"`
+	Total  float64 `json:"total"`
+}
+
+// RearPlaneOfArray estimates the irradiance reaching the back of a module
+// with a 2D view-factor model of a single row. The rear face sees isotropic
+// sky diffuse, direct sun when it is behind the module, and light reflected
+// by the ground (albedo arr.Albedo), less the beam missing from the module's
+// own shadow. The shadow's share of the rear view comes from the crossed
+// strings rule, so a higher MountingHeight moves it out of view. The ground
+// beneath the module likewise loses the sky diffuse the module blocks, taken
+// at the middle of its footprint.
+func RearPlaneOfArray(dni, dhi float64, sun SunPosition, arr Array) RearIrradiance {
+	if sun.Elevation <= 0 || arr.Horizon.Blocks(sun) {
+		dni = 0
+	}
+	tilt := deg2rad(arr.Tilt)
+	az := deg2rad(arr.Azimuth)
+	sinT, cosT := math.Sin(tilt), math.Cos(tilt)
+
+	s := sunVector(sun)
+	// Sun components along the facing direction and up, in the plane
+	// across the row.
+	sx := s.x*math.Sin(az) + s.y*math.Cos(az)
+	sz := s.z
+	cosAOI := sx*sinT + sz*cosT
+
+	beamH := 0.0
+	if sz > 0 {
+		beamH = dni * sz
+	}
+
+	var rear RearIrradiance
+	if cosAOI < 0 {
+		rear.Beam = dni * -cosAOI
+	}
+	rear.Sky = dhi * (1 - cosT) / 2
+
+	half := bifacialModuleLength / 2
+	height := arr.MountingHeight
+	if height == 0 {
+		height = DefaultMountingHeight
+	}
+	height = math.Max(height, half*sinT+minEdgeClearance)
+	// Lower edge on the facing side, upper edge behind it.
+	x1, z1 := half*cosT, height-half*sinT
+	x2, z2 := -half*cosT, height+half*sinT
+
+	shadowView := 0.0
+	if beamH > 0 {
+		a, b := x1-z1*sx/sz, x2-z2*sx/sz
+		if a > b {
+			a, b = b, a
+		}
+		// The rear face only sees ground behind the module's plane.
+		if sinT > 0 {
+			b = math.Min(b, x1+z1*cosT/sinT)
+		}
+		if b > a {
+			shadowView = stripViewFactor(x1, z1, x2, z2, a, b)
+		}
+	}
+	// Share of the sky hidden from the ground below the module's centre.
+	blocked := math.Abs(x1/math.Hypot(x1, z1)-x2/math.Hypot(x2, z2)) / 2
+	footprintView := 0.0
+	if cosT > 0 {
+		footprintView = stripViewFactor(x1, z1, x2, z2, x2, x1)
+	}
+
+	groundView := (1 + cosT) / 2
+	reflected := (dhi+beamH)*groundView - beamH*shadowView - dhi*blocked*footprintView
+	rear.Ground = arr.Albedo * math.Max(reflected, 0)
+
+	rear.Total = rear.Beam + rear.Sky + rear.Ground
+	return rear
+}
+
+// stripViewFactor is the view factor from the module cross-section
+// (x1,z1)-(x2,z2) to the ground strip [a, b] by Hottel's crossed strings.
+func stripViewFactor(x1, z1, x2, z2, a, b float64) float64 {
+	d := func(x, z, g float64) float64 { return math.Hypot(x-g, z) }
+	crossed := d(x1, z1, a) + d(x2, z2, b)
+	uncrossed := d(x1, z1, b) + d(x2, z2, a)
+	return math.Abs(crossed-uncrossed) / (2 * math.Hypot(x1-x2, z1-z2))
+}
+
+// bifacialGain is the extra effective irradiance the rear face contributes.
+func bifacialGain(panel SolarPanelData, rear RearIrradiance) float64 {
+	return panel.Bifaciality * rear.Total * (1 - rearStructureLoss)
+}
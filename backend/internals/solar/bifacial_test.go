@@ -0,0 +1,96 @@
+package solar
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+func TestRearPlaneOfArray_Overcast(t *testing.T) {
+	// Without beam the rear sees isotropic sky, and ground that the module
+	// itself darkens less the higher it is mounted.
+	sun := SunPosition{Elevation: 30, Azimuth: 180}
+	low := RearPlaneOfArray(0, 200, sun, Array{Tilt: 60, Azimuth: 180, Albedo: 0.3})
+	high := RearPlaneOfArray(0, 200, sun, Array{Tilt: 60, Azimuth: 180, Albedo: 0.3, MountingHeight: 5})
+	almostEqual(t, low.Beam, 0, 1e-9)
+	almostEqual(t, low.Sky, 200*0.25, 1e-9)
+	if unobstructed := 0.3 * 200 * 0.75; !(low.Ground < high.Ground && high.Ground < unobstructed) {
+		t.Fatalf("expected %.1f < %.1f < %.1f", low.Ground, high.Ground, unobstructed)
+	}
+}
+
+func TestRearPlaneOfArray_HeightMovesShadowOutOfView(t *testing.T) {
+	sun := SunPosition{Elevation: 50, Azimuth: 180}
+	prev := 0.0
+	for _, h := range []float64{1.2, 2, 4} {
+		arr := Array{Tilt: 30, Azimuth: 180, Albedo: 0.25, MountingHeight: h}
+		rear := RearPlaneOfArray(700, 150, sun, arr)
+		if rear.Ground <= prev {
+			t.Fatalf("expected rear ground irradiance to rise with height, got %.1f at %.1f m after %.1f", rear.Ground, h, prev)
+		}
+		prev = rear.Ground
+	}
+	// Unshaded ground is the upper bound.
+	ghi := 150 + 700*sunVector(sun).z
+	if limit := 0.25 * ghi * (1 + math.Cos(deg2rad(30))) / 2; prev >= limit {
+		t.Fatalf("rear ground %.1f should stay below the unshaded %.1f", prev, limit)
+	}
+}
+
+func TestRearPlaneOfArray_SunBehind(t *testing.T) {
+	// A vertical east-facing module catches the afternoon sun on its back.
+	arr := Array{Tilt: 90, Azimuth: 90, Albedo: DefaultAlbedo}
+	rear := RearPlaneOfArray(700, 100, SunPosition{Elevation: 30, Azimuth: 270}, arr)
+	almostEqual(t, rear.Beam, 700*math.Cos(deg2rad(30)), 1e-6)
+
+	arr.Horizon = Horizon{{Azimuth: 0, Elevation: 40}}
+	if rear := RearPlaneOfArray(700, 100, SunPosition{Elevation: 30, Azimuth: 270}, arr); rear.Beam != 0 {
+		t.Fatalf("expected the horizon to block the rear beam, got %.1f", rear.Beam)
+	}
+}
+
+func TestCalculateHourlyOutputForArray_Bifacial(t *testing.T) {
+	mono := SolarPanelData{MaximumPowerPmax: 400, TemperatureCoefficientPmax: -0.0035, NOCT_Temp: 45}
+	bifi := mono
+	bifi.Bifaciality = 0.7
+
+	var wp clients.WeatherPack
+	for h := 8; h <= 17; h++ {
+		wp.Hours = append(wp.Hours, clients.HourWeather{
+			Time:          time.Date(2025, time.June, 21, h, 0, 0, 0, time.UTC),
+			AmbientTemp:   20,
+			IrradianceGHI: 600,
+		})
+	}
+	arr := Array{Tilt: 30, Azimuth: 180, Albedo: 0.3, MountingHeight: 1.5}
+
+	monoPoints, monoWh, _, _, err := CalculateHourlyOutputForArray(mono, wp, 51.5, -0.12, arr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bifiPoints, bifiWh, _, _, err := CalculateHourlyOutputForArray(bifi, wp, 51.5, -0.12, arr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range monoPoints {
+		if p.RearPOA != 0 || p.BifacialGainWh != 0 {
+			t.Fatalf("expected no rear gain for a monofacial panel: %+v", p)
+		}
+	}
+
+	gain := 0.0
+	for _, p := range bifiPoints {
+		if p.RearPOA <= 0 {
+			t.Fatalf("expected rear irradiance at %s", p.Time)
+		}
+		gain += p.BifacialGainWh
+	}
+	if gain <= 0 || bifiWh <= monoWh {
+		t.Fatalf("expected a bifacial gain, got %.1f Wh (%.1f vs %.1f)", gain, bifiWh, monoWh)
+	}
+	if share := gain / bifiWh; share < 0.02 || share > 0.15 {
+		t.Fatalf("bifacial gain share %.3f outside the expected range", share)
+	}
+}
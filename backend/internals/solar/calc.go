@@ -1,7 +1,6 @@
 package solar
 
 import (
-	"errors"
 	"fmt"
 	"math"
 	"time"
@@ -13,26 +12,61 @@ type HourlyPoint struct {
 	Time           time.Time `json:"time"`
 	Ambient        float64   `json:"ambient"`
 	GHI            float64   `json:"ghi"`
+	DNI            float64   `json:"dni"`
+	DHI            float64   `json:"dhi"`
+	POA            float64   `json:"poa"`
+	EffectivePOA   float64   `json:"effectivePoa,omitempty"`
+	Wind           float64   `json:"wind,omitempty"`
+	CellTemp       float64   `json:"cellTemp,omitempty"`
+	ClearSkyGHI    float64   `json:"clearSkyGhi"`
+	ClearSkyWh     float64   `json:"clearSkyWh"`
+	ClearSkyIndex  float64   `json:"clearSkyIndex"`
+	ShadedFraction float64   `json:"shadedFraction,omitempty"`
+	ShadingLossWh  float64   `json:"shadingLossWh,omitempty"`
 	EnergyWh       float64   `json:"energyWh"`
 	EnergyWhLow    float64   `json:"energyWhLow"`
 	EnergyWhHigh   float64   `json:"energyWhHigh"`
 	CumulativeWh   float64   `json:"cumulativeWh"`
 	CumulativeLow  float64   `json:"cumulativeLow"`
 	CumulativeHigh float64   `json:"cumulativeHigh"`
+	DCWh           float64   `json:"dcWh,omitempty"`
+	ClippedWh      float64   `json:"clippedWh,omitempty"`
+	CurtailedWh    float64   `json:"curtailedWh,omitempty"`
+	LoadWh         float64   `json:"loadWh,omitempty"`
+	SelfConsumedWh float64   `json:"selfConsumedWh,omitempty"`
+	ExportWh       float64   `json:"exportWh,omitempty"`
+	ImportWh       float64   `json:"importWh,omitempty"`
+	// RearPOA and BifacialGainWh are set for bifacial panels; the gain is
+	// already part of EnergyWh.
+	RearPOA        float64 `json:"rearPoa,omitempty"`
+	BifacialGainWh float64 `json:"bifacialGainWh,omitempty"`
+	// SnowCoverage is the share of the array under snow; SnowLossWh is the
+	// output it cost, already taken out of EnergyWh.
+	SnowCoverage float64 `json:"snowCoverage,omitempty"`
+	SnowLossWh   float64 `json:"snowLossWh,omitempty"`
+	// SurfaceTilt and SurfaceAzimuth are set for tracking arrays.
+	SurfaceTilt    float64 `json:"surfaceTilt,omitempty"`
+	SurfaceAzimuth float64 `json:"surfaceAzimuth,omitempty"`
+	// BatteryChargeWh and BatteryDischargeWh are the hour's battery flows.
+	BatteryChargeWh    float64 `json:"batteryChargeWh,omitempty"`
+	BatteryDischargeWh float64 `json:"batteryDischargeWh,omitempty"`
+	// BatterySoC is the state of charge at the end of the hour, 0-1. It is
+	// nil without a battery, so an empty battery still reports 0.
+	BatterySoC *float64 `json:"batterySoc,omitempty"`
+	// Value is the hour's worth under a tariff, in the tariff's currency.
+	Value float64 `json:"value,omitempty"`
 }
 
-const lowBuffer = 0.9
+// lowBuffer and TiltBoostFactor set the fixed bands of a single weather run
+// on horizontal GHI. Plane-of-array runs already count the tilt gain, so they
+// use a symmetric fixedBandUncertainty instead; see BandsFixed.
+const (
+	lowBuffer            = 0.9
+	fixedBandUncertainty = 0.1
+)
 
 func EstimatedCellTemperature(panel SolarPanelData, ambientTemp, irradiance float64) (float64, error) {
-	if ambientTemp < -40 || ambientTemp > 85 {
-		return 0, errors.New("ambient temperature out of range")
-	}
-	if irradiance < 0 || irradiance > 1200 {
-		return 0, errors.New("irradiance out of range")
-	}
-
-	cellTemp := ambientTemp + ((irradiance / 800.0) * (panel.NOCT_Temp - 20.0))
-	return cellTemp, nil
+	return CellTemperature(NOCTModel{}, panel, ambientTemp, irradiance, 0)
 }
 func CalculateSolarPanelOutputByHour(
 	panel SolarPanelData,
@@ -42,7 +76,24 @@ func CalculateSolarPanelOutputByHour(
 	if err != nil {
 		return 0, err
 	}
+	return panelOutputWh(panel, irradiance, Tc), nil
+}
 
+// CalculateSolarPanelOutputWithModel is CalculateSolarPanelOutputByHour with
+// a chosen cell-temperature model; it also returns the cell temperature.
+func CalculateSolarPanelOutputWithModel(
+	panel SolarPanelData,
+	model CellTemperatureModel,
+	ambientTemp, irradiance, wind float64,
+) (energyWh, cellTemp float64, err error) {
+	Tc, err := CellTemperature(model, panel, ambientTemp, irradiance, wind)
+	if err != nil {
+		return 0, 0, err
+	}
+	return panelOutputWh(panel, irradiance, Tc), Tc, nil
+}
+
+func panelOutputWh(panel SolarPanelData, irradiance, Tc float64) float64 {
 	Pinst := panel.MaximumPowerPmax *
 		(irradiance / 1000.0) *
 		(1.0 + panel.TemperatureCoefficientPmax*(Tc-25.0))
@@ -51,7 +102,7 @@ func CalculateSolarPanelOutputByHour(
 	}
 
 	energyWh := Pinst * 1.0
-	return energyWh, nil
+	return energyWh
 }
 
 var boostEquatorial = [...]float64{
@@ -133,6 +184,7 @@ func CalculateHourlyOutputFromWeatherWithRange(
 			Time:           h.Time,
 			Ambient:        h.AmbientTemp,
 			GHI:            h.IrradianceGHI,
+			POA:            h.IrradianceGHI,
 			EnergyWh:       baseWh,
 			EnergyWhLow:    lowWh,
 			EnergyWhHigh:   highWh,
@@ -144,3 +196,156 @@ func CalculateHourlyOutputFromWeatherWithRange(
 
 	return points, totalBase, totalLow, totalHigh, nil
 }
+
+func CalculateHourlyOutputForArray(
+	panel SolarPanelData,
+	wp clients.WeatherPack,
+	lat, lon float64,
+	arr Array,
+) ([]HourlyPoint, float64, float64, float64, error) {
+	tempModel, ok := arr.TemperatureModel.Model()
+	if !ok {
+		return nil, 0, 0, 0, fmt.Errorf("unknown temperature model %q", arr.TemperatureModel)
+	}
+
+	points := make([]HourlyPoint, 0, len(wp.Hours))
+
+	var totalBase, totalLow, totalHigh float64
+	snow := newSnowCover(arr.InitialSnowCoverage)
+
+	for _, h := range wp.Hours {
+		mid := intervalMidpoint(h.Time)
+		sun := CalculateSunPosition(mid, lat, lon)
+		hourArr := arr.orientedAt(sun)
+		out, err := modelArrayHour(panel, tempModel, h, mid, sun, hourArr)
+		if err != nil {
+			return nil, 0, 0, 0, fmt.Errorf("hour %s: %w", h.Time.Format(time.RFC3339), err)
+		}
+		coverage := snow.step(h, out.irr, hourArr.Tilt)
+		snowLossWh := 0.0
+		if loss := snowLoss(coverage, arr.SnowStrings); loss > 0 {
+			snowLossWh = out.energyWh * loss
+			out = out.scaled(1 - loss)
+		}
+		baseWh := out.energyWh
+
+		// The clear-sky run keeps the real temperature and wind so the gap
+		// to the actual output is down to cloud alone.
+		cs := ClearSkyIneichen(sun.Zenith, mid, DefaultLinkeTurbidity, wp.Elevation)
+		clearHour := h
+		clearHour.IrradianceGHI, clearHour.IrradianceDNI, clearHour.IrradianceDHI = cs.GHI, cs.DNI, cs.DHI
+		clearHour.HasComponents = true
+		clearOut, err := modelArrayHour(panel, tempModel, clearHour, mid, sun, hourArr)
+		if err != nil {
+			return nil, 0, 0, 0, fmt.Errorf("hour %s: clear sky: %w", h.Time.Format(time.RFC3339), err)
+		}
+		// Snow on the panels is not cloud, so it applies to the clear-sky run too.
+		clearOut = clearOut.scaled(1 - snowLoss(coverage, arr.SnowStrings))
+
+		lowWh := baseWh * (1 - fixedBandUncertainty)
+		highWh := baseWh * (1 + fixedBandUncertainty)
+
+		totalBase += baseWh
+		totalLow += lowWh
+		totalHigh += highWh
+
+		var surfaceTilt, surfaceAzimuth float64
+		if arr.Tracker != nil {
+			surfaceTilt, surfaceAzimuth = hourArr.Tilt, hourArr.Azimuth
+		}
+
+		points = append(points, HourlyPoint{
+			Time:           h.Time,
+			Ambient:        h.AmbientTemp,
+			GHI:            h.IrradianceGHI,
+			DNI:            out.poa.DNI,
+			DHI:            out.poa.DHI,
+			POA:            out.irr,
+			RearPOA:        out.rear,
+			SurfaceTilt:    surfaceTilt,
+			SurfaceAzimuth: surfaceAzimuth,
+			EffectivePOA:   out.effective,
+			Wind:           h.WindSpeed,
+			CellTemp:       out.cellTemp,
+			ClearSkyGHI:    cs.GHI,
+			ClearSkyWh:     clearOut.energyWh,
+			ClearSkyIndex:  ClearSkyIndex(h.IrradianceGHI, cs.GHI),
+			ShadedFraction: out.poa.ShadedFraction,
+			ShadingLossWh:  out.shadingLossWh,
+			BifacialGainWh: out.bifacialGainWh,
+			SnowCoverage:   coverage,
+			SnowLossWh:     snowLossWh,
+			EnergyWh:       baseWh,
+			EnergyWhLow:    lowWh,
+			EnergyWhHigh:   highWh,
+			CumulativeWh:   totalBase,
+			CumulativeLow:  totalLow,
+			CumulativeHigh: totalHigh,
+		})
+	}
+
+	return points, totalBase, totalLow, totalHigh, nil
+}
+
+type arrayHour struct {
+	poa           POAIrradiance
+	irr           float64 // POA clamped to the range the models accept
+	effective     float64
+	cellTemp      float64
+	energyWh      float64
+	shadingLossWh float64
+	// rear and bifacialGainWh are zero for monofacial panels.
+	rear           float64
+	bifacialGainWh float64
+}
+
+// scaled keeps share k of the hour's output, e.g. what snow leaves uncovered.
+func (a arrayHour) scaled(k float64) arrayHour {
+	a.energyWh *= k
+	a.shadingLossWh *= k
+	a.bifacialGainWh *= k
+	return a
+}
+
+// modelArrayHour runs one hour through transposition, optical losses and the
+// cell-temperature model. The cell heats with all incident light, but only
+// the effective irradiance is converted.
+func modelArrayHour(
+	panel SolarPanelData,
+	tempModel CellTemperatureModel,
+	h clients.HourWeather,
+	mid time.Time,
+	sun SunPosition,
+	arr Array,
+) (arrayHour, error) {
+	poa := planeOfArrayAt(h, mid, sun, arr)
+	irr := math.Min(poa.Total, maxIrradiance)
+	eff := math.Min(EffectiveIrradiance(poa, sun.Zenith, arr), maxIrradiance)
+
+	var rear RearIrradiance
+	if panel.Bifaciality > 0 {
+		rear = RearPlaneOfArray(poa.DNI, poa.DHI, sun, arr)
+	}
+	tc, err := CellTemperature(tempModel, panel, h.AmbientTemp, math.Min(irr+rear.Total, maxIrradiance), h.WindSpeed)
+	if err != nil {
+		return arrayHour{}, err
+	}
+	out := arrayHour{poa: poa, irr: irr, effective: eff, cellTemp: tc, energyWh: panelOutputWh(panel, eff, tc)}
+	if gain := bifacialGain(panel, rear); gain > 0 {
+		front := out.energyWh
+		out.rear = rear.Total
+		out.energyWh = panelOutputWh(panel, math.Min(eff+gain, maxIrradiance), tc)
+		out.bifacialGainWh = math.Max(out.energyWh-front, 0)
+	}
+
+	if poa.ShadedFraction > 0 {
+		unobstructed := arr
+		unobstructed.Horizon, unobstructed.NearShading = nil, nil
+		unshaded, err := modelArrayHour(panel, tempModel, h, mid, sun, unobstructed)
+		if err != nil {
+			return arrayHour{}, err
+		}
+		out.shadingLossWh = math.Max(unshaded.energyWh-out.energyWh, 0)
+	}
+	return out, nil
+}
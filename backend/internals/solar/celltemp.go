@@ -0,0 +1,107 @@
+package solar
+
+import (
+	"errors"
+	"math"
+)
+
+// CellTemperatureModel estimates cell temperature (°C) from ambient
+// temperature (°C), plane-of-array irradiance (W/m²) and wind speed (m/s).
+type CellTemperatureModel interface {
+	CellTemperature(panel SolarPanelData, ambient, poa, wind float64) float64
+}
+
+// TemperatureModelName selects a CellTemperatureModel in requests.
+type TemperatureModelName string
+
+const (
+	TemperatureNOCT   TemperatureModelName = "noct"
+	TemperatureFaiman TemperatureModelName = "faiman"
+	TemperatureSAPM   TemperatureModelName = "sapm"
+	TemperaturePVsyst TemperatureModelName = "pvsyst"
+)
+
+func (n TemperatureModelName) Valid() bool {
+	_, ok := n.Model()
+	return ok
+}
+
+// Model returns the named model with its default coefficients; the empty
+// name means NOCT.
+func (n TemperatureModelName) Model() (CellTemperatureModel, bool) {
+	switch n {
+	case "", TemperatureNOCT:
+		return NOCTModel{}, true
+	case TemperatureFaiman:
+		return DefaultFaiman(), true
+	case TemperatureSAPM:
+		return DefaultSAPM(), true
+	case TemperaturePVsyst:
+		return DefaultPVsyst(), true
+	}
+	return nil, false
+}
+
+// NOCTModel scales the panel's NOCT rise linearly with irradiance and
+// ignores wind.
+type NOCTModel struct{}
+
+func (NOCTModel) CellTemperature(panel SolarPanelData, ambient, poa, _ float64) float64 {
+	return ambient + ((poa / 800.0) * (panel.NOCT_Temp - 20.0))
+}
+
+// FaimanModel is Faiman (2008): Tc = Ta + E / (U0 + U1·ws).
+type FaimanModel struct {
+	U0 float64 // W/m²K
+	U1 float64 // W/m³sK
+}
+
+func DefaultFaiman() FaimanModel { return FaimanModel{U0: 25.0, U1: 6.84} }
+
+func (m FaimanModel) CellTemperature(_ SolarPanelData, ambient, poa, wind float64) float64 {
+	return ambient + poa/(m.U0+m.U1*math.Max(wind, 0))
+}
+
+// SAPMModel is the Sandia module/cell temperature model (King et al. 2004).
+type SAPMModel struct {
+	A      float64
+	B      float64 // s/m
+	DeltaT float64 // °C, cell-to-back-of-module difference at 1000 W/m²
+}
+
+// DefaultSAPM uses the open-rack glass/glass coefficients.
+func DefaultSAPM() SAPMModel { return SAPMModel{A: -3.47, B: -0.0594, DeltaT: 3} }
+
+func (m SAPMModel) CellTemperature(_ SolarPanelData, ambient, poa, wind float64) float64 {
+	module := poa*math.Exp(m.A+m.B*math.Max(wind, 0)) + ambient
+	return module + poa/1000*m.DeltaT
+}
+
+// PVsystModel is the PVsyst heat-loss model:
+// Tc = Ta + α·E·(1 − η) / (Uc + Uv·ws).
+type PVsystModel struct {
+	Uc         float64 // W/m²K
+	Uv         float64 // W/m³sK
+	Absorption float64
+	Efficiency float64
+}
+
+// DefaultPVsyst uses PVsyst's free-standing defaults.
+func DefaultPVsyst() PVsystModel {
+	return PVsystModel{Uc: 29.0, Uv: 0, Absorption: 0.9, Efficiency: 0.1}
+}
+
+func (m PVsystModel) CellTemperature(_ SolarPanelData, ambient, poa, wind float64) float64 {
+	return ambient + m.Absorption*poa*(1-m.Efficiency)/(m.Uc+m.Uv*math.Max(wind, 0))
+}
+
+// CellTemperature range-checks the inputs before evaluating model.
+func CellTemperature(model CellTemperatureModel, panel SolarPanelData, ambientTemp, irradiance, wind float64) (float64, error) {
+	if ambientTemp < -40 || ambientTemp > 85 {
+		return 0, errors.New("ambient temperature out of range")
+	}
+	if irradiance < 0 || irradiance > 1200 {
+		return 0, errors.New("irradiance out of range")
+	}
+	return model.CellTemperature(panel, ambientTemp, irradiance, wind), nil
+}
@@ -0,0 +1,65 @@
+package solar
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+func TestCellTemperatureModels(t *testing.T) {
+	p := SolarPanelData{NOCT_Temp: 45}
+
+	almostEqual(t, NOCTModel{}.CellTemperature(p, 20, 800, 5), 45, 1e-9)
+	almostEqual(t, DefaultFaiman().CellTemperature(p, 20, 1000, 1), 20+1000/31.84, 1e-9)
+	almostEqual(t, DefaultSAPM().CellTemperature(p, 20, 1000, 0), 20+1000*math.Exp(-3.47)+3, 1e-9)
+	almostEqual(t, DefaultPVsyst().CellTemperature(p, 20, 1000, 0), 20+0.9*1000*0.9/29, 1e-9)
+}
+
+func TestCellTemperatureWindCools(t *testing.T) {
+	p := SolarPanelData{NOCT_Temp: 45}
+	for _, n := range []TemperatureModelName{TemperatureFaiman, TemperatureSAPM} {
+		m, _ := n.Model()
+		calm := m.CellTemperature(p, 25, 900, 0)
+		breezy := m.CellTemperature(p, 25, 900, 6)
+		if breezy >= calm {
+			t.Fatalf("%s: expected wind to cool the cell, calm %.2f breezy %.2f", n, calm, breezy)
+		}
+	}
+}
+
+func TestTemperatureModelName(t *testing.T) {
+	if m, ok := TemperatureModelName("").Model(); !ok || m != (NOCTModel{}) {
+		t.Fatalf("expected empty name to default to NOCT, got %#v", m)
+	}
+	if TemperatureModelName("bogus").Valid() {
+		t.Fatal("expected unknown model to be invalid")
+	}
+}
+
+func TestCalculateHourlyOutputForArray_TemperatureModel(t *testing.T) {
+	panel := SolarPanelData{MaximumPowerPmax: 400, TemperatureCoefficientPmax: -0.004, NOCT_Temp: 45}
+	wp := clients.WeatherPack{Hours: []clients.HourWeather{{
+		Time:          time.Date(2025, time.March, 20, 12, 30, 0, 0, time.UTC),
+		AmbientTemp:   25,
+		IrradianceGHI: 800,
+		WindSpeed:     8,
+	}}}
+
+	noct, _, _, _, err := CalculateHourlyOutputForArray(panel, wp, 0, 0, Array{})
+	if err != nil {
+		t.Fatalf("noct: %v", err)
+	}
+	faiman, _, _, _, err := CalculateHourlyOutputForArray(panel, wp, 0, 0, Array{TemperatureModel: TemperatureFaiman})
+	if err != nil {
+		t.Fatalf("faiman: %v", err)
+	}
+	if faiman[0].CellTemp >= noct[0].CellTemp || faiman[0].EnergyWh <= noct[0].EnergyWh {
+		t.Fatalf("expected a windy Faiman cell to run cooler: noct %+v faiman %+v", noct[0], faiman[0])
+	}
+
+	if _, _, _, _, err := CalculateHourlyOutputForArray(panel, wp, 0, 0, Array{TemperatureModel: "bogus"}); err == nil {
+		t.Fatal("expected error for unknown temperature model")
+	}
+}
@@ -0,0 +1,64 @@
+package solar
+
+import (
+	"math"
+	"time"
+)
+
+// ClearSky is cloudless-sky irradiance in W/m².
+type ClearSky struct {
+	GHI float64 `json:"ghi"`
+	DNI float64 `json:"dni"`
+	DHI float64 `json:"dhi"`
+}
+
+// Without a turbidity climatology a moderate Linke turbidity is assumed; it
+// suits rural mid-latitude sites and errs low for very clean air.
+const (
+	DefaultLinkeTurbidity = 3.0
+	minClearSkyGHI        = 10.0 // below this the clear-sky index is noise
+)
+
+// ClearSkyIneichen is the Ineichen & Perez (2002) clear-sky model for a site
+// at altitude metres above sea level.
+func ClearSkyIneichen(zenith float64, ts time.Time, linke, altitude float64) ClearSky {
+	cosZ := math.Cos(deg2rad(zenith))
+	if zenith >= 90 || cosZ <= 0 {
+		return ClearSky{}
+	}
+	am := RelativeAirMass(zenith) * stationPressure(altitude) / 101325
+	return ineichen(cosZ, am, extraterrestrialDNI(ts), linke, altitude)
+}
+
+// ineichen evaluates the model for absolute air mass am and extraterrestrial
+// irradiance e0.
+func ineichen(cosZ, am, e0, linke, altitude float64) ClearSky {
+	fh1 := math.Exp(-altitude / 8000)
+	fh2 := math.Exp(-altitude / 1250)
+	cg1 := 5.09e-5*altitude + 0.868
+	cg2 := 3.92e-5*altitude + 0.0387
+
+	ghi := cg1 * e0 * cosZ * math.Max(math.Exp(-cg2*am*(fh1+fh2*(linke-1))+0.01*math.Pow(am, 1.8)), 0)
+
+	b := 0.664 + 0.163/fh1
+	dni := e0 * math.Max(b*math.Exp(-0.09*am*(linke-1)), 0)
+	// The beam can never exceed what the global model allows.
+	dniCap := ghi * math.Max((1-(0.1-0.2*math.Exp(-linke))/(0.1+0.882/fh1))/cosZ, 0)
+	dni = math.Min(dni, dniCap)
+
+	return ClearSky{GHI: ghi, DNI: dni, DHI: math.Max(ghi-dni*cosZ, 0)}
+}
+
+// ClearSkyIndex is measured over clear-sky GHI, or 0 when the sun is too low
+// for the ratio to mean anything.
+func ClearSkyIndex(ghi, clearGHI float64) float64 {
+	if clearGHI < minClearSkyGHI {
+		return 0
+	}
+	return ghi / clearGHI
+}
+
+// stationPressure is the standard-atmosphere pressure in Pa at altitude m.
+func stationPressure(altitude float64) float64 {
+	return 101325 * math.Pow(1-2.25577e-5*altitude, 5.25588)
+}
@@ -0,0 +1,64 @@
+package solar
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+func TestClearSkyIneichen(t *testing.T) {
+	ts := time.Date(2025, time.June, 21, 12, 0, 0, 0, time.UTC)
+
+	cs := ClearSkyIneichen(30, ts, DefaultLinkeTurbidity, 0)
+	if cs.GHI < 800 || cs.GHI > 950 {
+		t.Fatalf("implausible clear-sky GHI at 30°: %.1f", cs.GHI)
+	}
+	almostEqual(t, cs.DNI*math.Cos(deg2rad(30))+cs.DHI, cs.GHI, 1e-6)
+
+	if hazy := ClearSkyIneichen(30, ts, 6, 0); hazy.GHI >= cs.GHI {
+		t.Fatalf("expected turbid air to lower GHI: %.1f vs %.1f", hazy.GHI, cs.GHI)
+	}
+	if high := ClearSkyIneichen(30, ts, DefaultLinkeTurbidity, 2000); high.GHI <= cs.GHI {
+		t.Fatalf("expected altitude to raise GHI: %.1f vs %.1f", high.GHI, cs.GHI)
+	}
+	if night := ClearSkyIneichen(95, ts, DefaultLinkeTurbidity, 0); night != (ClearSky{}) {
+		t.Fatalf("expected no irradiance below the horizon, got %+v", night)
+	}
+}
+
+func TestIneichen_PvlibReference(t *testing.T) {
+	// pvlib's test_ineichen_scalar_input: zenith 10°, absolute air mass 1,
+	// Linke turbidity 3 and 1364 W/m² extraterrestrial, there without the
+	// exp(0.01·AM^1.8) term, which at AM 1 is a factor of exp(0.01).
+	cosZ := math.Cos(deg2rad(10))
+	cs := ineichen(cosZ, 1, 1364, 3, 0)
+	almostEqual(t, cs.GHI, 1038.159219*math.Exp(0.01), 1e-3)
+	almostEqual(t, cs.DNI, 942.2081860378344, 1e-3)
+	almostEqual(t, cs.DHI, cs.GHI-cs.DNI*cosZ, 1e-9)
+}
+
+func TestClearSkyIndex(t *testing.T) {
+	almostEqual(t, ClearSkyIndex(400, 800), 0.5, 1e-9)
+	almostEqual(t, ClearSkyIndex(5, 2), 0, 1e-9)
+}
+
+func TestCalculateHourlyOutputForArray_ClearSky(t *testing.T) {
+	panel := SolarPanelData{MaximumPowerPmax: 400, TemperatureCoefficientPmax: -0.004, NOCT_Temp: 45}
+	wp := clients.WeatherPack{Hours: []clients.HourWeather{{
+		Time:          time.Date(2025, time.June, 21, 13, 0, 0, 0, time.UTC),
+		AmbientTemp:   20,
+		IrradianceGHI: 300,
+	}}}
+
+	points, _, _, _, err := CalculateHourlyOutputForArray(panel, wp, 51.5, 0, Array{Tilt: 30, Azimuth: 180})
+	if err != nil {
+		t.Fatalf("CalculateHourlyOutputForArray error: %v", err)
+	}
+	p := points[0]
+	if p.ClearSkyGHI < 700 || p.ClearSkyWh <= p.EnergyWh {
+		t.Fatalf("expected a cloudy hour well below clear sky: %+v", p)
+	}
+	almostEqual(t, p.ClearSkyIndex, 300/p.ClearSkyGHI, 1e-9)
+}
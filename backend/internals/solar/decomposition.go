@@ -0,0 +1,103 @@
+package solar
+
+import (
+	"math"
+	"time"
+)
+
+type DecompositionModel string
+
+const (
+	DecompositionErbs DecompositionModel = "erbs"
+	DecompositionDISC DecompositionModel = "disc"
+)
+
+const (
+	maxDecompZenith = 87.0
+	maxAirMass      = 12.0
+)
+
+func (m DecompositionModel) Valid() bool {
+	switch m {
+	case "", DecompositionErbs, DecompositionDISC:
+		return true
+	}
+	return false
+}
+
+// DecomposeGHI derives DNI and DHI from GHI and the sun's zenith angle.
+// Both models keep DNI*cos(zenith) + DHI equal to GHI.
+func DecomposeGHI(model DecompositionModel, ghi, zenith float64, ts time.Time) (dni, dhi float64) {
+	if ghi <= 0 {
+		return 0, 0
+	}
+	cosZ := math.Cos(deg2rad(zenith))
+	if zenith >= maxDecompZenith || cosZ <= 0 {
+		return 0, ghi
+	}
+
+	kt := clearnessIndex(ghi, cosZ, ts)
+
+	switch model {
+	case DecompositionDISC:
+		dni = discDNI(kt, zenith, ts)
+	default:
+		dni = ghi * (1 - erbsDiffuseFraction(kt)) / cosZ
+	}
+
+	dhi = ghi - dni*cosZ
+	if dhi < 0 {
+		dhi = 0
+		dni = ghi / cosZ
+	}
+	return dni, dhi
+}
+
+func clearnessIndex(ghi, cosZ float64, ts time.Time) float64 {
+	kt := ghi / (extraterrestrialDNI(ts) * cosZ)
+	return math.Max(0, math.Min(kt, 1))
+}
+
+// erbsDiffuseFraction is the Erbs, Klein & Duffie (1982) correlation.
+func erbsDiffuseFraction(kt float64) float64 {
+	switch {
+	case kt <= 0.22:
+		return 1 - 0.09*kt
+	case kt <= 0.80:
+		return 0.9511 - 0.1604*kt + 4.388*kt*kt - 16.638*math.Pow(kt, 3) + 12.336*math.Pow(kt, 4)
+	default:
+		return 0.165
+	}
+}
+
+// discDNI is Maxwell's (1987) DISC model at standard pressure.
+func discDNI(kt, zenith float64, ts time.Time) float64 {
+	am := math.Min(RelativeAirMass(zenith), maxAirMass)
+
+	var a, b, c float64
+	if kt <= 0.6 {
+		a = 0.512 - 1.56*kt + 2.286*kt*kt - 2.222*kt*kt*kt
+		b = 0.37 + 0.962*kt
+		c = -0.28 + 0.932*kt - 2.048*kt*kt
+	} else {
+		a = -5.743 + 21.77*kt - 27.49*kt*kt + 11.56*kt*kt*kt
+		b = 41.4 - 118.5*kt + 66.05*kt*kt + 31.9*kt*kt*kt
+		c = -47.01 + 184.2*kt - 222.0*kt*kt + 73.81*kt*kt*kt
+	}
+	deltaKn := a + b*math.Exp(c*am)
+	knc := 0.866 - 0.122*am + 0.0121*am*am - 0.000653*math.Pow(am, 3) + 0.000014*math.Pow(am, 4)
+
+	dni := (knc - deltaKn) * extraterrestrialDNI(ts)
+	if dni < 0 {
+		return 0
+	}
+	return dni
+}
+
+// RelativeAirMass uses the Kasten & Young (1989) formula; zenith in degrees.
+func RelativeAirMass(zenith float64) float64 {
+	if zenith >= 90 {
+		return math.Inf(1)
+	}
+	return 1 / (math.Cos(deg2rad(zenith)) + 0.50572*math.Pow(96.07995-zenith, -1.6364))
+}
@@ -0,0 +1,45 @@
+package solar
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDecomposeGHI_ConservesGHI(t *testing.T) {
+	ts := time.Date(2025, time.June, 21, 12, 0, 0, 0, time.UTC)
+	zenith := 30.0
+	ghi := 800.0
+
+	for _, m := range []DecompositionModel{DecompositionErbs, DecompositionDISC} {
+		dni, dhi := DecomposeGHI(m, ghi, zenith, ts)
+		if dni <= 0 || dhi <= 0 {
+			t.Fatalf("%s: expected positive components, got dni=%.2f dhi=%.2f", m, dni, dhi)
+		}
+		almostEqual(t, dni*math.Cos(deg2rad(zenith))+dhi, ghi, 1e-6)
+	}
+}
+
+func TestDecomposeGHI_OvercastIsDiffuse(t *testing.T) {
+	ts := time.Date(2025, time.June, 21, 12, 0, 0, 0, time.UTC)
+
+	for _, m := range []DecompositionModel{DecompositionErbs, DecompositionDISC} {
+		dni, dhi := DecomposeGHI(m, 100, 30, ts)
+		if dhi < 90 {
+			t.Fatalf("%s: expected mostly diffuse under overcast, got dni=%.2f dhi=%.2f", m, dni, dhi)
+		}
+	}
+}
+
+func TestDecomposeGHI_LowSun(t *testing.T) {
+	ts := time.Date(2025, time.June, 21, 4, 0, 0, 0, time.UTC)
+	dni, dhi := DecomposeGHI(DecompositionDISC, 20, 88, ts)
+	if dni != 0 || dhi != 20 {
+		t.Fatalf("expected all diffuse near horizon, got dni=%.2f dhi=%.2f", dni, dhi)
+	}
+}
+
+func TestRelativeAirMass(t *testing.T) {
+	almostEqual(t, RelativeAirMass(0), 1.0, 1e-3)
+	almostEqual(t, RelativeAirMass(60), 1.99, 1e-2)
+}
@@ -0,0 +1,97 @@
+package solar
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Exceedance levels: P90 is the output exceeded in 90% of members, so it is
+// the 10th percentile of the ensemble.
+const (
+	p90Quantile = 0.1
+	p50Quantile = 0.5
+	p10Quantile = 0.9
+)
+
+// BandSource names where a curve's low/high bands come from.
+type BandSource string
+
+const (
+	// BandsEnsemble bands are the P90/P10 of a weather ensemble.
+	BandsEnsemble BandSource = "ensemble"
+	// BandsFixed bands scale a single weather run by a fixed uncertainty
+	// either side. Archive and TMY weather has no ensemble, and
+	// forecasts fall back to them when the ensemble is unavailable.
+	BandsFixed BandSource = "fixed"
+)
+
+// EnsembleQuantiles collapses per-member hourly curves into one curve where
+// EnergyWh is the P50, EnergyWhLow the P90 and EnergyWhHigh the P10 of the
+// members' EnergyWh. Cumulative bands are quantiles of the members'
+// cumulative energy, so the last point gives the daily P90/P50/P10 rather
+// than a sum of hourly quantiles. Weather fields carry the member median;
+// clear-sky fields come from the first member.
+func EnsembleQuantiles(members [][]HourlyPoint) ([]HourlyPoint, error) {
+	if len(members) == 0 {
+		return nil, errors.New("ensemble has no members")
+	}
+	n := len(members[0])
+	for _, m := range members[1:] {
+		if len(m) != n {
+			return nil, errors.New("ensemble members differ in length")
+		}
+	}
+
+	vals := make([]float64, len(members))
+	at := func(i int, field func(HourlyPoint) float64) []float64 {
+		for j, m := range members {
+			vals[j] = field(m[i])
+		}
+		sort.Float64s(vals)
+		return vals
+	}
+
+	out := make([]HourlyPoint, n)
+	for i := range out {
+		p := HourlyPoint{Time: members[0][i].Time}
+		p.Ambient = quantile(at(i, func(h HourlyPoint) float64 { return h.Ambient }), p50Quantile)
+		p.GHI = quantile(at(i, func(h HourlyPoint) float64 { return h.GHI }), p50Quantile)
+		p.DNI = quantile(at(i, func(h HourlyPoint) float64 { return h.DNI }), p50Quantile)
+		p.DHI = quantile(at(i, func(h HourlyPoint) float64 { return h.DHI }), p50Quantile)
+		p.POA = quantile(at(i, func(h HourlyPoint) float64 { return h.POA }), p50Quantile)
+		p.RearPOA = quantile(at(i, func(h HourlyPoint) float64 { return h.RearPOA }), p50Quantile)
+		p.SurfaceTilt, p.SurfaceAzimuth = members[0][i].SurfaceTilt, members[0][i].SurfaceAzimuth
+		p.Wind = quantile(at(i, func(h HourlyPoint) float64 { return h.Wind }), p50Quantile)
+		p.CellTemp = quantile(at(i, func(h HourlyPoint) float64 { return h.CellTemp }), p50Quantile)
+		p.ClearSkyGHI = members[0][i].ClearSkyGHI
+		p.ClearSkyWh = members[0][i].ClearSkyWh
+		p.ClearSkyIndex = ClearSkyIndex(p.GHI, p.ClearSkyGHI)
+		p.DCWh = quantile(at(i, func(h HourlyPoint) float64 { return h.DCWh }), p50Quantile)
+		p.ClippedWh = quantile(at(i, func(h HourlyPoint) float64 { return h.ClippedWh }), p50Quantile)
+		p.ShadedFraction = quantile(at(i, func(h HourlyPoint) float64 { return h.ShadedFraction }), p50Quantile)
+		p.ShadingLossWh = quantile(at(i, func(h HourlyPoint) float64 { return h.ShadingLossWh }), p50Quantile)
+		p.BifacialGainWh = quantile(at(i, func(h HourlyPoint) float64 { return h.BifacialGainWh }), p50Quantile)
+		p.SnowCoverage = quantile(at(i, func(h HourlyPoint) float64 { return h.SnowCoverage }), p50Quantile)
+		p.SnowLossWh = quantile(at(i, func(h HourlyPoint) float64 { return h.SnowLossWh }), p50Quantile)
+
+		e := at(i, func(h HourlyPoint) float64 { return h.EnergyWh })
+		p.EnergyWhLow, p.EnergyWh, p.EnergyWhHigh = quantile(e, p90Quantile), quantile(e, p50Quantile), quantile(e, p10Quantile)
+
+		c := at(i, func(h HourlyPoint) float64 { return h.CumulativeWh })
+		p.CumulativeLow, p.CumulativeWh, p.CumulativeHigh = quantile(c, p90Quantile), quantile(c, p50Quantile), quantile(c, p10Quantile)
+		out[i] = p
+	}
+	return out, nil
+}
+
+// quantile interpolates linearly between order statistics of sorted values.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
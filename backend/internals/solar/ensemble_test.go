@@ -0,0 +1,51 @@
+package solar
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEnsembleQuantiles(t *testing.T) {
+	start := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	// Eleven members with constant hourly output 0..10 Wh over two hours.
+	var members [][]HourlyPoint
+	for m := 0; m <= 10; m++ {
+		e := float64(m)
+		members = append(members, []HourlyPoint{
+			{Time: start, GHI: 100 * e, EnergyWh: e, CumulativeWh: e},
+			{Time: start.Add(time.Hour), GHI: 100 * e, EnergyWh: e, CumulativeWh: 2 * e},
+		})
+	}
+
+	out, err := EnsembleQuantiles(members)
+	if err != nil {
+		t.Fatalf("EnsembleQuantiles error: %v", err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected 2 points, got %d", len(out))
+	}
+	almostEqual(t, out[0].EnergyWhLow, 1, 1e-9)
+	almostEqual(t, out[0].EnergyWh, 5, 1e-9)
+	almostEqual(t, out[0].EnergyWhHigh, 9, 1e-9)
+	almostEqual(t, out[0].GHI, 500, 1e-9)
+
+	base, low, high := Totals(out)
+	almostEqual(t, low, 2, 1e-9)
+	almostEqual(t, base, 10, 1e-9)
+	almostEqual(t, high, 18, 1e-9)
+}
+
+func TestEnsembleQuantiles_Errors(t *testing.T) {
+	if _, err := EnsembleQuantiles(nil); err == nil {
+		t.Fatal("expected error for empty ensemble")
+	}
+	a := []HourlyPoint{{}, {}}
+	if _, err := EnsembleQuantiles([][]HourlyPoint{a, a[:1]}); err == nil {
+		t.Fatal("expected error for mismatched members")
+	}
+}
+
+func TestQuantileInterpolates(t *testing.T) {
+	almostEqual(t, quantile([]float64{0, 10}, 0.25), 2.5, 1e-9)
+	almostEqual(t, quantile([]float64{7}, 0.9), 7, 1e-9)
+}
@@ -0,0 +1,131 @@
+package solar
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// EVCharge is a charging session: NeedKWh must be delivered at up to
+// ChargerKW between Start and Deadline.
+type EVCharge struct {
+	NeedKWh   float64   `json:"needKWh"`
+	ChargerKW float64   `json:"chargerKW"`
+	Start     time.Time `json:"start"`
+	Deadline  time.Time `json:"deadline"`
+}
+
+type EVHour struct {
+	Time      time.Time `json:"time"`
+	SurplusWh float64   `json:"surplusWh"`
+	SolarWh   float64   `json:"solarWh"`
+	GridWh    float64   `json:"gridWh"`
+}
+
+// EVPlan lists every hour in the window. GridWh is the shortfall solar could
+// not cover; UnmetWh is what the charger cannot deliver before the deadline.
+type EVPlan struct {
+	Hours      []EVHour `json:"hours"`
+	NeedWh     float64  `json:"needWh"`
+	SolarWh    float64  `json:"solarWh"`
+	GridWh     float64  `json:"gridWh"`
+	UnmetWh    float64  `json:"unmetWh,omitempty"`
+	SolarShare float64  `json:"solarShare"`
+}
+
+func (ev EVCharge) Validate() error {
+	switch {
+	case ev.NeedKWh <= 0:
+		return errors.New("EV energy need must be positive")
+	case ev.ChargerKW <= 0:
+		return errors.New("charger power must be positive")
+	case !ev.Deadline.After(ev.Start):
+		return errors.New("deadline must be after the start")
+	}
+	return nil
+}
+
+// PlanEVCharging fills the hours with the most PV surplus first. Surplus is
+// the hour's export when netOfLoad is set (points carry a load, see
+// ApplyLoad), otherwise its whole EnergyWh, plus anything curtailed at an
+// export limit. Any remainder is drawn from the grid in the cheapest hours
+// by rate, or as late as possible when rate is nil, leaving room for a later
+// forecast to move it onto solar. Hours cut by Start or Deadline offer only
+// the part of their surplus and charger energy that falls inside the window.
+func PlanEVCharging(points []HourlyPoint, ev EVCharge, netOfLoad bool, rate func(time.Time) float64) (EVPlan, error) {
+	if err := ev.Validate(); err != nil {
+		return EVPlan{}, err
+	}
+
+	plan := EVPlan{NeedWh: ev.NeedKWh * 1000}
+	var chargerWh []float64
+	for _, p := range points {
+		// Hour-ending points: the share of the hour inside the window.
+		from, to := p.Time.Add(-time.Hour), p.Time
+		if ev.Start.After(from) {
+			from = ev.Start
+		}
+		if ev.Deadline.Before(to) {
+			to = ev.Deadline
+		}
+		share := to.Sub(from).Hours()
+		if share <= 0 {
+			continue
+		}
+		surplus := p.EnergyWh
+		if netOfLoad {
+			surplus = p.ExportWh
+		}
+		surplus += p.CurtailedWh
+		plan.Hours = append(plan.Hours, EVHour{Time: p.Time, SurplusWh: math.Max(surplus, 0) * share})
+		chargerWh = append(chargerWh, ev.ChargerKW*1000*share)
+	}
+	if len(plan.Hours) == 0 {
+		return EVPlan{}, errors.New("no forecast hours before the deadline")
+	}
+
+	remaining := plan.NeedWh
+
+	order := make([]int, len(plan.Hours))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return plan.Hours[order[a]].SurplusWh > plan.Hours[order[b]].SurplusWh
+	})
+	for _, i := range order {
+		if remaining <= 0 {
+			break
+		}
+		h := &plan.Hours[i]
+		h.SolarWh = math.Min(remaining, math.Min(h.SurplusWh, chargerWh[i]))
+		remaining -= h.SolarWh
+		plan.SolarWh += h.SolarWh
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		ta, tb := plan.Hours[order[a]].Time, plan.Hours[order[b]].Time
+		if rate != nil {
+			if ra, rb := rate(intervalMidpoint(ta)), rate(intervalMidpoint(tb)); ra != rb {
+				return ra < rb
+			}
+		}
+		return ta.After(tb)
+	})
+	for _, i := range order {
+		if remaining <= 0 {
+			break
+		}
+		h := &plan.Hours[i]
+		h.GridWh = math.Min(remaining, chargerWh[i]-h.SolarWh)
+		remaining -= h.GridWh
+		plan.GridWh += h.GridWh
+	}
+
+	plan.UnmetWh = math.Max(remaining, 0)
+	if delivered := plan.SolarWh + plan.GridWh; delivered > 0 {
+		plan.SolarShare = plan.SolarWh / delivered
+	}
+	return plan, nil
+}
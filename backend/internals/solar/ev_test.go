@@ -0,0 +1,111 @@
+package solar
+
+import (
+	"testing"
+	"time"
+)
+
+func evDay() []HourlyPoint {
+	day := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	energy := map[int]float64{10: 1000, 11: 3000, 12: 5000, 13: 4000, 14: 2000}
+	points := make([]HourlyPoint, 24)
+	for h := range points {
+		points[h] = HourlyPoint{Time: day.Add(time.Duration(h+1) * time.Hour), EnergyWh: energy[h+1]}
+	}
+	return points
+}
+
+func TestPlanEVCharging(t *testing.T) {
+	day := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	ev := EVCharge{NeedKWh: 10, ChargerKW: 3.6, Start: day.Add(8 * time.Hour), Deadline: day.Add(18 * time.Hour)}
+
+	plan, err := PlanEVCharging(evDay(), ev, false, nil)
+	if err != nil {
+		t.Fatalf("PlanEVCharging error: %v", err)
+	}
+	if len(plan.Hours) != 10 {
+		t.Fatalf("expected 10 hours in the window, got %d", len(plan.Hours))
+	}
+	// The two sunniest hours at the charger limit plus 2.8 kWh of the third.
+	almostEqual(t, plan.SolarWh, 10000, 1e-9)
+	almostEqual(t, plan.GridWh, 0, 1e-9)
+
+	ev.NeedKWh = 16
+	plan, err = PlanEVCharging(evDay(), ev, false, nil)
+	if err != nil {
+		t.Fatalf("PlanEVCharging error: %v", err)
+	}
+	// Solar: 3.6 + 3.6 + 3 + 2 + 1 = 13.2 kWh; the rest from the grid in the
+	// last hour before the deadline.
+	almostEqual(t, plan.SolarWh, 13200, 1e-9)
+	almostEqual(t, plan.GridWh, 2800, 1e-9)
+	last := plan.Hours[len(plan.Hours)-1]
+	if last.GridWh != 2800 {
+		t.Fatalf("expected the grid top-up at the end of the window: %+v", plan.Hours)
+	}
+	almostEqual(t, plan.SolarShare, 13200.0/16000, 1e-9)
+}
+
+func TestPlanEVCharging_RateAndUnmet(t *testing.T) {
+	day := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	ev := EVCharge{NeedKWh: 50, ChargerKW: 7, Start: day, Deadline: day.Add(6 * time.Hour)}
+	cheap := func(ts time.Time) float64 {
+		if ts.Hour() < 2 {
+			return 0.1
+		}
+		return 0.3
+	}
+
+	plan, err := PlanEVCharging(evDay(), ev, false, cheap)
+	if err != nil {
+		t.Fatalf("PlanEVCharging error: %v", err)
+	}
+	almostEqual(t, plan.GridWh, 42000, 1e-9)
+	almostEqual(t, plan.UnmetWh, 8000, 1e-9)
+
+	ev.NeedKWh = 10
+	plan, _ = PlanEVCharging(evDay(), ev, false, cheap)
+	// Both night hours are cheap; the later one fills first.
+	if plan.Hours[1].GridWh != 7000 || plan.Hours[0].GridWh != 3000 {
+		t.Fatalf("expected the cheap night hours first: %+v", plan.Hours[:3])
+	}
+}
+
+func TestPlanEVCharging_PartialHours(t *testing.T) {
+	day := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	// A quarter of the 10:00-11:00 hour and half of the 13:00-14:00 hour.
+	ev := EVCharge{NeedKWh: 50, ChargerKW: 4, Start: day.Add(10*time.Hour + 45*time.Minute), Deadline: day.Add(13*time.Hour + 30*time.Minute)}
+
+	plan, err := PlanEVCharging(evDay(), ev, false, nil)
+	if err != nil {
+		t.Fatalf("PlanEVCharging error: %v", err)
+	}
+	if len(plan.Hours) != 4 {
+		t.Fatalf("expected 4 hours in the window, got %d", len(plan.Hours))
+	}
+	first, last := plan.Hours[0], plan.Hours[3]
+	almostEqual(t, first.SurplusWh, 3000*0.25, 1e-9)
+	almostEqual(t, first.SolarWh+first.GridWh, 1000, 1e-9)
+	almostEqual(t, last.SurplusWh, 2000*0.5, 1e-9)
+	almostEqual(t, last.SolarWh+last.GridWh, 2000, 1e-9)
+	// 2.75 hours of charging in all.
+	almostEqual(t, plan.SolarWh+plan.GridWh, 11000, 1e-9)
+}
+
+func TestPlanEVCharging_NetOfLoad(t *testing.T) {
+	points := evDay()
+	ApplyLoad(points, flatLoad(1500))
+
+	day := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	ev := EVCharge{NeedKWh: 20, ChargerKW: 11, Start: day, Deadline: day.Add(24 * time.Hour)}
+	plan, err := PlanEVCharging(points, ev, true, nil)
+	if err != nil {
+		t.Fatalf("PlanEVCharging error: %v", err)
+	}
+	// Exports: 1.5 + 3.5 + 2.5 + 0.5 kWh.
+	almostEqual(t, plan.SolarWh, 8000, 1e-9)
+
+	if _, err := PlanEVCharging(points, EVCharge{NeedKWh: 5, ChargerKW: 7, Start: day, Deadline: day}, true, nil); err == nil {
+		t.Fatal("expected error for an empty window")
+	}
+}
@@ -0,0 +1,197 @@
+package solar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HorizonPoint is the terrain elevation in degrees above the horizontal at
+// an azimuth in degrees clockwise from north.
+type HorizonPoint struct {
+	Azimuth   float64 `json:"azimuth"`
+	Elevation float64 `json:"elevation"`
+}
+
+// Horizon is a far-shading profile, sorted by azimuth. Between points the
+// elevation is interpolated linearly, wrapping through north.
+type Horizon []HorizonPoint
+
+const maxHorizonPoints = 720
+
+func NewHorizon(points []HorizonPoint) (Horizon, error) {
+	if len(points) > maxHorizonPoints {
+		return nil, fmt.Errorf("horizon has more than %d points", maxHorizonPoints)
+	}
+	h := make(Horizon, len(points))
+	copy(h, points)
+	for _, p := range h {
+		if p.Azimuth < 0 || p.Azimuth >= 360 {
+			return nil, fmt.Errorf("horizon azimuth %.1f out of range", p.Azimuth)
+		}
+		if p.Elevation < -10 || p.Elevation >= 90 {
+			return nil, fmt.Errorf("horizon elevation %.1f out of range", p.Elevation)
+		}
+	}
+	sort.Slice(h, func(i, j int) bool { return h[i].Azimuth < h[j].Azimuth })
+	return h, nil
+}
+
+// ElevationAt returns the horizon height towards azimuth; an empty profile is
+// a flat horizon.
+func (h Horizon) ElevationAt(azimuth float64) float64 {
+	switch len(h) {
+	case 0:
+		return 0
+	case 1:
+		return h[0].Elevation
+	}
+	az := wrap360(azimuth)
+
+	i := sort.Search(len(h), func(i int) bool { return h[i].Azimuth > az })
+	lo, hi := h[(i-1+len(h))%len(h)], h[i%len(h)]
+
+	span := wrap360(hi.Azimuth - lo.Azimuth)
+	if span == 0 {
+		return lo.Elevation
+	}
+	return lerp(lo.Elevation, hi.Elevation, wrap360(az-lo.Azimuth)/span)
+}
+
+// Blocks reports whether the sun at the given position is behind terrain.
+func (h Horizon) Blocks(sun SunPosition) bool {
+	return len(h) > 0 && sun.Elevation < h.ElevationAt(sun.Azimuth)
+}
+
+// ParseHorizon reads a PVGIS horizon file in either of its two layouts: the
+// tabular printhorizon output (columns "A" and "H_hor", azimuth measured from
+// south with east negative), or the single-column userhorizon upload of
+// elevations equally spaced clockwise from north (one per line, or all on a
+// single line). Headerless files with two columns per row are read as
+// azimuth (clockwise from north) and elevation pairs.
+func ParseHorizon(r io.Reader) (Horizon, error) {
+	sc := bufio.NewScanner(r)
+
+	var points []HorizonPoint
+	var rows [][]float64
+	azCol, elCol := -1, -1
+
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool { return r == '\t' || r == ',' || r == ';' || r == ' ' })
+
+		if azCol < 0 {
+			for i, f := range fields {
+				switch f {
+				case "A":
+					azCol = i
+				case "H_hor":
+					elCol = i
+				}
+			}
+			if azCol >= 0 && elCol >= 0 {
+				continue
+			}
+			azCol = -1
+		} else {
+			if len(fields) <= azCol || len(fields) <= elCol {
+				break
+			}
+			a, errA := strconv.ParseFloat(fields[azCol], 64)
+			e, errE := strconv.ParseFloat(fields[elCol], 64)
+			if errA != nil || errE != nil {
+				// The table is followed by a free-text legend.
+				break
+			}
+			points = append(points, HorizonPoint{Azimuth: wrap360(a + 180), Elevation: e})
+			continue
+		}
+
+		row := make([]float64, 0, len(fields))
+		for _, f := range fields {
+			v, err := strconv.ParseFloat(f, 64)
+			if err != nil {
+				// Metadata lines ahead of the table, e.g. "Latitude: 45.8".
+				rows, row = nil, nil
+				break
+			}
+			row = append(row, v)
+		}
+		if row != nil {
+			rows = append(rows, row)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("horizon: %w", err)
+	}
+
+	if len(points) == 0 {
+		var err error
+		if points, err = headerlessHorizon(rows); err != nil {
+			return nil, err
+		}
+	}
+	if len(points) == 0 {
+		return nil, errors.New("horizon: no data")
+	}
+
+	// printhorizon repeats the first azimuth at +180°.
+	points = dedupeAzimuths(points)
+	return NewHorizon(points)
+}
+
+// headerlessHorizon reads rows without a column header: one elevation per
+// row, or a single row of elevations, equally spaced from north; or
+// azimuth/elevation pairs. Any other mix of row widths is ambiguous.
+func headerlessHorizon(rows [][]float64) ([]HorizonPoint, error) {
+	width := 0
+	for i, row := range rows {
+		if i > 0 && len(row) != width {
+			return nil, fmt.Errorf("horizon: row %d has %d values, expected %d", i+1, len(row), width)
+		}
+		width = len(row)
+	}
+
+	var points []HorizonPoint
+	switch {
+	case len(rows) == 0:
+	case width == 2 && len(rows) > 1:
+		for _, row := range rows {
+			points = append(points, HorizonPoint{Azimuth: wrap360(row[0]), Elevation: row[1]})
+		}
+	case width == 1 || len(rows) == 1:
+		var elevations []float64
+		for _, row := range rows {
+			elevations = append(elevations, row...)
+		}
+		step := 360 / float64(len(elevations))
+		for i, e := range elevations {
+			points = append(points, HorizonPoint{Azimuth: float64(i) * step, Elevation: e})
+		}
+	default:
+		return nil, fmt.Errorf("horizon: rows of %d values are neither elevations nor azimuth/elevation pairs", width)
+	}
+	return points, nil
+}
+
+func dedupeAzimuths(points []HorizonPoint) []HorizonPoint {
+	seen := make(map[float64]bool, len(points))
+	out := points[:0]
+	for _, p := range points {
+		key := math.Round(p.Azimuth*1e6) / 1e6
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, p)
+	}
+	return out
+}
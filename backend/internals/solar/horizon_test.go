@@ -0,0 +1,120 @@
+package solar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+func TestHorizonElevationAt(t *testing.T) {
+	h, err := NewHorizon([]HorizonPoint{
+		{Azimuth: 270, Elevation: 20},
+		{Azimuth: 90, Elevation: 10},
+		{Azimuth: 0, Elevation: 0},
+	})
+	if err != nil {
+		t.Fatalf("NewHorizon error: %v", err)
+	}
+	almostEqual(t, h.ElevationAt(45), 5, 1e-9)
+	almostEqual(t, h.ElevationAt(180), 15, 1e-9)
+	almostEqual(t, h.ElevationAt(315), 10, 1e-9) // wraps through north
+	almostEqual(t, Horizon(nil).ElevationAt(123), 0, 1e-9)
+
+	if _, err := NewHorizon([]HorizonPoint{{Azimuth: 360}}); err == nil {
+		t.Fatal("expected error for azimuth out of range")
+	}
+}
+
+const samplePrintHorizon = `Latitude (decimal degrees):	46.500
+Longitude (decimal degrees):	9.800
+Horizon height (degrees) and sun paths at the winter and summer solstices
+
+A	H_hor	A_sun(w)	H_sun(w)	A_sun(s)	H_sun(s)
+-180.0	10.0	0.0	0.0	-119.7	0.0
+-90.0	20.0	0.0	0.0	-90.0	20.1
+0.0	30.0	0.0	20.0	0.0	67.0
+90.0	5.0	0.0	0.0	90.0	20.1
+180.0	10.0	0.0	0.0	119.7	0.0
+
+A: Azimuth (0 = S, 90 = W, -90 = E) (degree)
+H_hor: Horizon height (degree)
+`
+
+func TestParseHorizon_PrintHorizon(t *testing.T) {
+	h, err := ParseHorizon(strings.NewReader(samplePrintHorizon))
+	if err != nil {
+		t.Fatalf("ParseHorizon error: %v", err)
+	}
+	if len(h) != 4 {
+		t.Fatalf("expected 4 points after dropping the repeated north, got %+v", h)
+	}
+	almostEqual(t, h.ElevationAt(180), 30, 1e-9) // PVGIS A=0 is south
+	almostEqual(t, h.ElevationAt(90), 20, 1e-9)
+	almostEqual(t, h.ElevationAt(270), 5, 1e-9)
+}
+
+func TestParseHorizon_UserHorizon(t *testing.T) {
+	h, err := ParseHorizon(strings.NewReader("0,10,20,10\n"))
+	if err != nil {
+		t.Fatalf("ParseHorizon error: %v", err)
+	}
+	if len(h) != 4 || h[2].Azimuth != 180 || h[2].Elevation != 20 {
+		t.Fatalf("unexpected horizon: %+v", h)
+	}
+	if _, err := ParseHorizon(strings.NewReader("no data here\n")); err == nil {
+		t.Fatal("expected error for empty horizon")
+	}
+}
+
+func TestParseHorizon_AzimuthElevationPairs(t *testing.T) {
+	h, err := ParseHorizon(strings.NewReader("0,5\n90,10\n180,30\n270,15\n360,5\n"))
+	if err != nil {
+		t.Fatalf("ParseHorizon error: %v", err)
+	}
+	if len(h) != 4 {
+		t.Fatalf("expected 4 points after folding 360 onto north, got %+v", h)
+	}
+	almostEqual(t, h.ElevationAt(180), 30, 1e-9)
+	almostEqual(t, h.ElevationAt(270), 15, 1e-9)
+
+	if _, err := ParseHorizon(strings.NewReader("0,5,1\n90,10,2\n")); err == nil {
+		t.Fatal("expected error for rows of three values")
+	}
+	if _, err := ParseHorizon(strings.NewReader("0,5\n10\n")); err == nil {
+		t.Fatal("expected error for rows of mixed width")
+	}
+}
+
+func TestCalculateHourlyOutputForArray_Horizon(t *testing.T) {
+	panel := SolarPanelData{MaximumPowerPmax: 400, TemperatureCoefficientPmax: -0.004, NOCT_Temp: 45}
+	wp := clients.WeatherPack{Hours: []clients.HourWeather{{
+		Time:          time.Date(2025, time.December, 21, 13, 0, 0, 0, time.UTC),
+		AmbientTemp:   0,
+		IrradianceGHI: 200,
+		IrradianceDNI: 500,
+		IrradianceDHI: 50,
+		HasComponents: true,
+	}}}
+	arr := Array{Tilt: 35, Azimuth: 180, Albedo: DefaultAlbedo}
+
+	open, _, _, _, err := CalculateHourlyOutputForArray(panel, wp, 46.5, 0, arr)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	// A 30° ridge to the south hides the low winter sun.
+	arr.Horizon = Horizon{{Azimuth: 90, Elevation: 30}, {Azimuth: 270, Elevation: 30}}
+	shaded, _, _, _, err := CalculateHourlyOutputForArray(panel, wp, 46.5, 0, arr)
+	if err != nil {
+		t.Fatalf("shaded: %v", err)
+	}
+
+	if shaded[0].EnergyWh >= open[0].EnergyWh/2 {
+		t.Fatalf("expected most output lost behind the ridge: open %.1f shaded %.1f", open[0].EnergyWh, shaded[0].EnergyWh)
+	}
+	almostEqual(t, shaded[0].ShadingLossWh, open[0].EnergyWh-shaded[0].EnergyWh, 1e-9)
+	if open[0].ShadingLossWh != 0 {
+		t.Fatalf("expected no loss without a horizon, got %.2f", open[0].ShadingLossWh)
+	}
+}
@@ -0,0 +1,106 @@
+package solar
+
+import "math"
+
+// IAMModel names an incidence-angle modifier; the empty model applies none.
+type IAMModel string
+
+const (
+	IAMASHRAE   IAMModel = "ashrae"
+	IAMPhysical IAMModel = "physical"
+)
+
+const (
+	ashraeB0 = 0.05
+
+	// Physical model defaults: glass refractive index, extinction
+	// coefficient (1/m) and glazing thickness (m).
+	glassIndex     = 1.526
+	glassExtinct   = 4.0
+	glassThickness = 0.002
+)
+
+// SAPM spectral polynomial for a typical mono-Si module, in absolute air mass.
+var spectralCoeffs = [...]float64{0.9281, 0.06615, -0.01384, 0.001298, -4.6e-05}
+
+func (m IAMModel) Valid() bool {
+	switch m {
+	case "", IAMASHRAE, IAMPhysical:
+		return true
+	}
+	return false
+}
+
+// Modifier returns the fraction of irradiance transmitted at an angle of
+// incidence aoi (degrees) relative to normal incidence.
+func (m IAMModel) Modifier(aoi float64) float64 {
+	switch {
+	case m == "":
+		return 1
+	case aoi >= 90:
+		return 0
+	case m == IAMASHRAE:
+		return ashraeIAM(aoi)
+	case m == IAMPhysical:
+		return physicalIAM(aoi)
+	}
+	return 1
+}
+
+// ashraeIAM is Souka & Safwat (1966) as adopted by ASHRAE.
+func ashraeIAM(aoi float64) float64 {
+	return clamp01(1 - ashraeB0*(1/math.Cos(deg2rad(aoi))-1))
+}
+
+// physicalIAM combines Fresnel reflection at the air-glass interface with
+// Bouguer absorption in the glazing (De Soto et al. 2006).
+func physicalIAM(aoi float64) float64 {
+	tau := func(theta float64) float64 {
+		if theta < 1e-6 {
+			r := (glassIndex - 1) / (glassIndex + 1)
+			return math.Exp(-glassExtinct*glassThickness) * (1 - r*r)
+		}
+		thetaR := math.Asin(math.Sin(theta) / glassIndex)
+		s := math.Sin(thetaR-theta) / math.Sin(thetaR+theta)
+		p := math.Tan(thetaR-theta) / math.Tan(thetaR+theta)
+		return math.Exp(-glassExtinct*glassThickness/math.Cos(thetaR)) * (1 - 0.5*(s*s+p*p))
+	}
+	return clamp01(tau(deg2rad(aoi)) / tau(0))
+}
+
+// diffuseAngles are the Brandemuehl & Beckman (1980) effective incidence
+// angles for sky and ground diffuse on a plane tilted tilt degrees.
+func diffuseAngles(tilt float64) (sky, ground float64) {
+	sky = 59.7 - 0.1388*tilt + 0.001497*tilt*tilt
+	ground = 90 - 0.5788*tilt + 0.002693*tilt*tilt
+	return sky, ground
+}
+
+// SpectralFactor is the SAPM air-mass modifier for a sea-level site, clamped
+// at zero; air mass is capped at maxAirMass so sunrise hours stay finite.
+func SpectralFactor(zenith float64) float64 {
+	am := math.Min(RelativeAirMass(zenith), maxAirMass)
+	f, x := 0.0, 1.0
+	for _, c := range spectralCoeffs {
+		f += c * x
+		x *= am
+	}
+	return math.Max(f, 0)
+}
+
+// EffectiveIrradiance is the POA irradiance that reaches the cells after
+// reflection losses and, when arr.Spectral is set, spectral mismatch.
+func EffectiveIrradiance(poa POAIrradiance, zenith float64, arr Array) float64 {
+	eff := poa.Total
+	if arr.IAM != "" {
+		aoi := rad2deg(math.Acos(math.Max(-1, math.Min(poa.CosAOI, 1))))
+		skyAOI, groundAOI := diffuseAngles(arr.Tilt)
+		eff = poa.Beam*arr.IAM.Modifier(aoi) +
+			poa.Sky*arr.IAM.Modifier(skyAOI) +
+			poa.Ground*arr.IAM.Modifier(groundAOI)
+	}
+	if arr.Spectral && eff > 0 {
+		eff *= SpectralFactor(zenith)
+	}
+	return eff
+}
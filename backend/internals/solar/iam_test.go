@@ -0,0 +1,80 @@
+package solar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+func TestIAMModifiers(t *testing.T) {
+	almostEqual(t, IAMASHRAE.Modifier(0), 1, 1e-9)
+	almostEqual(t, IAMASHRAE.Modifier(60), 0.95, 1e-9)
+	almostEqual(t, IAMPhysical.Modifier(0), 1, 1e-9)
+	almostEqual(t, IAMModel("").Modifier(85), 1, 1e-9)
+
+	prev := 1.0
+	for aoi := 10.0; aoi < 90; aoi += 10 {
+		m := IAMPhysical.Modifier(aoi)
+		if m > prev || m < 0 {
+			t.Fatalf("physical IAM not decreasing at %.0f°: %.4f after %.4f", aoi, m, prev)
+		}
+		prev = m
+	}
+	if m := IAMPhysical.Modifier(80); m > 0.7 {
+		t.Fatalf("expected strong reflection loss at 80°, got %.3f", m)
+	}
+	if IAMASHRAE.Modifier(90) != 0 || IAMPhysical.Modifier(95) != 0 {
+		t.Fatal("expected no transmission at or beyond grazing incidence")
+	}
+	if IAMModel("bogus").Valid() {
+		t.Fatal("expected unknown IAM model to be invalid")
+	}
+}
+
+func TestSpectralFactor(t *testing.T) {
+	// AM1.5 is the reference spectrum, so the modifier is close to one.
+	almostEqual(t, SpectralFactor(48.19), 1, 0.005)
+	for z := 0.0; z < 90; z += 5 {
+		if f := SpectralFactor(z); f < 0.9 || f > 1.1 {
+			t.Fatalf("spectral factor out of range at zenith %.0f: %.3f", z, f)
+		}
+	}
+}
+
+func TestEffectiveIrradiance(t *testing.T) {
+	poa := POAIrradiance{Beam: 500, Sky: 100, Ground: 20, Total: 620, CosAOI: 0.3}
+
+	if got := EffectiveIrradiance(poa, 70, Array{Tilt: 30}); got != 620 {
+		t.Fatalf("expected no losses without a model, got %.2f", got)
+	}
+	withIAM := EffectiveIrradiance(poa, 70, Array{Tilt: 30, IAM: IAMPhysical})
+	if withIAM >= 620 || withIAM < 400 {
+		t.Fatalf("unexpected effective irradiance with IAM: %.2f", withIAM)
+	}
+	withSpectral := EffectiveIrradiance(poa, 70, Array{Tilt: 30, IAM: IAMPhysical, Spectral: true})
+	almostEqual(t, withSpectral, withIAM*SpectralFactor(70), 1e-9)
+}
+
+func TestCalculateHourlyOutputForArray_IAMMorning(t *testing.T) {
+	panel := SolarPanelData{MaximumPowerPmax: 400, TemperatureCoefficientPmax: -0.004, NOCT_Temp: 45}
+	wp := clients.WeatherPack{Hours: []clients.HourWeather{{
+		Time:          time.Date(2025, time.June, 21, 7, 0, 0, 0, time.UTC),
+		AmbientTemp:   15,
+		IrradianceGHI: 300,
+	}}}
+	arr := Array{Tilt: 35, Azimuth: 180}
+
+	plain, _, _, _, err := CalculateHourlyOutputForArray(panel, wp, 51.5, 0, arr)
+	if err != nil {
+		t.Fatalf("plain: %v", err)
+	}
+	arr.IAM = IAMPhysical
+	withIAM, _, _, _, err := CalculateHourlyOutputForArray(panel, wp, 51.5, 0, arr)
+	if err != nil {
+		t.Fatalf("iam: %v", err)
+	}
+	if withIAM[0].EnergyWh >= plain[0].EnergyWh || withIAM[0].EffectivePOA >= withIAM[0].POA {
+		t.Fatalf("expected IAM to cut an early-morning hour: plain %+v iam %+v", plain[0], withIAM[0])
+	}
+}
@@ -0,0 +1,144 @@
+package solar
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// InverterData holds the Sandia inverter model parameters published in the
+// CEC inverter list (as distributed with NREL SAM).
+type InverterData struct {
+	Name     string  `json:"name"`
+	Vac      float64 `json:"vac"`
+	Paco     float64 `json:"paco"`
+	Pdco     float64 `json:"pdco"`
+	Vdco     float64 `json:"vdco"`
+	Pso      float64 `json:"pso"`
+	C0       float64 `json:"c0"`
+	C1       float64 `json:"c1"`
+	C2       float64 `json:"c2"`
+	C3       float64 `json:"c3"`
+	Pnt      float64 `json:"pnt"`
+	Vdcmax   float64 `json:"vdcmax"`
+	Idcmax   float64 `json:"idcmax"`
+	MpptLow  float64 `json:"mppt_low"`
+	MpptHigh float64 `json:"mppt_high"`
+}
+
+const inverterDataPath = "data/inverter_data.json"
+
+// ACPower evaluates the Sandia inverter model at the nominal DC voltage, since
+// the module model does not track string voltage. Output is clipped at Paco.
+func (d InverterData) ACPower(dcW float64) (acW, clippedW float64) {
+	if dcW <= d.Pso || d.Pdco <= d.Pso {
+		return 0, 0
+	}
+	a, b, c := d.Pdco, d.Pso, d.C0
+	acW = (d.Paco/(a-b)-c*(a-b))*(dcW-b) + c*(dcW-b)*(dcW-b)
+	acW = math.Max(acW, 0)
+
+	if acW > d.Paco {
+		clippedW = acW - d.Paco
+		acW = d.Paco
+	}
+	return acW, clippedW
+}
+
+// ParseCECInverters reads the CEC inverter CSV. The first row names the
+// columns; SAM's units row and "[0]" variable-name row are skipped.
+func ParseCECInverters(r io.Reader) (map[string]InverterData, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	cols, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cec: %w", err)
+	}
+	idx := map[string]int{}
+	for i, c := range cols {
+		idx[strings.TrimSpace(c)] = i
+	}
+	for _, n := range []string{"Name", "Paco", "Pdco", "Pso", "C0"} {
+		if _, ok := idx[n]; !ok {
+			return nil, fmt.Errorf("cec: missing column %q", n)
+		}
+	}
+
+	num := func(rec []string, col string) (float64, error) {
+		i, ok := idx[col]
+		if !ok || i >= len(rec) || strings.TrimSpace(rec[i]) == "" {
+			return 0, nil
+		}
+		return strconv.ParseFloat(strings.TrimSpace(rec[i]), 64)
+	}
+
+	out := make(map[string]InverterData)
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cec: %w", err)
+		}
+		name := strings.TrimSpace(rec[idx["Name"]])
+		if name == "" || name == "Units" || strings.HasPrefix(name, "[") {
+			continue
+		}
+
+		d := InverterData{Name: name}
+		fields := []struct {
+			col string
+			dst *float64
+		}{
+			{"Vac", &d.Vac}, {"Paco", &d.Paco}, {"Pdco", &d.Pdco}, {"Vdco", &d.Vdco},
+			{"Pso", &d.Pso}, {"C0", &d.C0}, {"C1", &d.C1}, {"C2", &d.C2}, {"C3", &d.C3},
+			{"Pnt", &d.Pnt}, {"Vdcmax", &d.Vdcmax}, {"Idcmax", &d.Idcmax},
+			{"Mppt_low", &d.MpptLow}, {"Mppt_high", &d.MpptHigh},
+		}
+		for _, f := range fields {
+			if *f.dst, err = num(rec, f.col); err != nil {
+				return nil, fmt.Errorf("cec: %s %s: %w", name, f.col, err)
+			}
+		}
+		if d.Paco <= 0 || d.Pdco <= 0 {
+			continue
+		}
+		out[name] = d
+	}
+
+	if len(out) == 0 {
+		return nil, errors.New("cec: no inverters found")
+	}
+	return out, nil
+}
+
+func LoadInverterData() (map[string]InverterData, error) {
+	data := make(map[string]InverterData)
+	jsonData, err := os.ReadFile(inverterDataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(jsonData, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func SaveInverterDataToFile(data map[string]InverterData) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(inverterDataPath, jsonData, 0644)
+}
@@ -0,0 +1,57 @@
+package solar
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleCEC = `Name,Vac,Pso,Paco,Pdco,Vdco,C0,C1,C2,C3,Pnt,Vdcmax,Idcmax,Mppt_low,Mppt_high,CEC_Date,CEC_Type
+Units,V,W,W,W,V,1/W,1/V,1/V,1/V,W,V,A,V,V,,
+[0],inv_snl_ac_voltage,inv_snl_pso,inv_snl_paco,inv_snl_pdco,inv_snl_vdco,inv_snl_c0,inv_snl_c1,inv_snl_c2,inv_snl_c3,inv_snl_pnt,inv_snl_vdcmax,inv_snl_idcmax,inv_snl_mppt_low,inv_snl_mppt_hi,inv_cec_date,inv_cec_type
+ABB: MICRO-0.25-I-OUTD-US-208 [208V],208,2.089607,250,259.5225,40.24223,-4.1e-05,-9.1e-05,0.000494,-0.013171,0.075,50,6.448907,30,50,,Utility Interactive
+SMA America: SB5000TL-US-22 [240V],240,27.28,5000,5188.6,328.8,-5.0e-06,3.1e-05,0.000839,0.00058,1.5,600,16.2,175,480,,Utility Interactive
+`
+
+func TestParseCECInverters(t *testing.T) {
+	data, err := ParseCECInverters(strings.NewReader(sampleCEC))
+	if err != nil {
+		t.Fatalf("ParseCECInverters error: %v", err)
+	}
+	if len(data) != 2 {
+		t.Fatalf("expected 2 inverters, got %d", len(data))
+	}
+	sma, ok := data["SMA America: SB5000TL-US-22 [240V]"]
+	if !ok {
+		t.Fatal("SMA inverter missing")
+	}
+	if sma.Paco != 5000 || sma.Pdco != 5188.6 || sma.Pso != 27.28 || sma.MpptHigh != 480 {
+		t.Fatalf("unexpected fields: %+v", sma)
+	}
+}
+
+func TestInverterDataACPower(t *testing.T) {
+	data, err := ParseCECInverters(strings.NewReader(sampleCEC))
+	if err != nil {
+		t.Fatalf("ParseCECInverters error: %v", err)
+	}
+	sma := data["SMA America: SB5000TL-US-22 [240V]"]
+
+	ac, clipped := sma.ACPower(sma.Pdco)
+	almostEqual(t, ac, sma.Paco, 1e-6)
+	almostEqual(t, clipped, 0, 1e-6)
+
+	ac, _ = sma.ACPower(2500)
+	if eff := ac / 2500; eff < 0.94 || eff > 0.99 {
+		t.Fatalf("unexpected part-load efficiency %.4f", eff)
+	}
+
+	ac, clipped = sma.ACPower(7000)
+	almostEqual(t, ac, sma.Paco, 1e-9)
+	if clipped <= 0 {
+		t.Fatal("expected clipping above Pdco")
+	}
+
+	if ac, _ := sma.ACPower(10); ac != 0 {
+		t.Fatalf("expected no output below Pso, got %.2f", ac)
+	}
+}
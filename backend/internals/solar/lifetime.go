@@ -0,0 +1,102 @@
+package solar
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Degradation is the linear warranty curve most datasheets publish: output
+// drops by FirstYear in year one and by Annual each year after.
+type Degradation struct {
+	FirstYear float64 `json:"firstYear"`
+	Annual    float64 `json:"annual"`
+	Years     int     `json:"years"`
+}
+
+// Typical crystalline-silicon warranty terms, used when a datasheet gives none.
+var DefaultDegradation = Degradation{FirstYear: 0.02, Annual: 0.0055, Years: 25}
+
+const maxLifetimeYears = 50
+
+// Degradation returns the panel's warranty curve, filling unpublished terms
+// from DefaultDegradation.
+func (p SolarPanelData) Degradation() Degradation {
+	d := Degradation{FirstYear: p.FirstYearDegradation, Annual: p.AnnualDegradation, Years: p.WarrantyYears}
+	if d.FirstYear <= 0 {
+		d.FirstYear = DefaultDegradation.FirstYear
+	}
+	if d.Annual <= 0 {
+		d.Annual = DefaultDegradation.Annual
+	}
+	if d.Years <= 0 {
+		d.Years = DefaultDegradation.Years
+	}
+	return d
+}
+
+// Factor is the share of first-day output left in the given year (1-based),
+// averaged over the year as end-of-year figures would understate it.
+func (d Degradation) Factor(year int) float64 {
+	if year < 1 {
+		return 1
+	}
+	start := 0.0
+	if year > 1 {
+		start = d.FirstYear + d.Annual*float64(year-2)
+	}
+	end := d.FirstYear + d.Annual*float64(year-1)
+	return math.Max(0, 1-(start+end)/2)
+}
+
+// BlendDegradation weights several panels' curves by their share of the
+// array's rating. A linear curve mixes exactly, so the blend matches the sum
+// of the parts year by year.
+func BlendDegradation(curves []Degradation, weights []float64) Degradation {
+	var out Degradation
+	total := 0.0
+	for i, d := range curves {
+		out.FirstYear += d.FirstYear * weights[i]
+		out.Annual += d.Annual * weights[i]
+		total += weights[i]
+		if d.Years > out.Years {
+			out.Years = d.Years
+		}
+	}
+	if total > 0 {
+		out.FirstYear /= total
+		out.Annual /= total
+	}
+	return out
+}
+
+type LifetimeYear struct {
+	Year          int     `json:"year"`
+	Factor        float64 `json:"factor"`
+	KWh           float64 `json:"kWh"`
+	CumulativeKWh float64 `json:"cumulativeKWh"`
+}
+
+// ProjectLifetime scales a first-year baseline by the degradation curve for
+// each of years years.
+func ProjectLifetime(baselineKWh float64, d Degradation, years int) ([]LifetimeYear, error) {
+	if baselineKWh < 0 {
+		return nil, errors.New("baseline yield must not be negative")
+	}
+	if years < 1 || years > maxLifetimeYears {
+		return nil, fmt.Errorf("lifetime must be between 1 and %d years", maxLifetimeYears)
+	}
+	if d.FirstYear < 0 || d.FirstYear >= 1 || d.Annual < 0 || d.Annual >= 1 {
+		return nil, errors.New("degradation rates must be fractions between 0 and 1")
+	}
+
+	out := make([]LifetimeYear, years)
+	cum := 0.0
+	for i := range out {
+		f := d.Factor(i + 1)
+		kwh := baselineKWh * f
+		cum += kwh
+		out[i] = LifetimeYear{Year: i + 1, Factor: f, KWh: kwh, CumulativeKWh: cum}
+	}
+	return out, nil
+}
@@ -0,0 +1,49 @@
+package solar
+
+import "testing"
+
+func TestPanelDegradationDefaults(t *testing.T) {
+	d := SolarPanelData{FirstYearDegradation: 0.01}.Degradation()
+	if d.FirstYear != 0.01 || d.Annual != DefaultDegradation.Annual || d.Years != DefaultDegradation.Years {
+		t.Fatalf("unexpected degradation: %+v", d)
+	}
+}
+
+func TestProjectLifetime(t *testing.T) {
+	d := Degradation{FirstYear: 0.02, Annual: 0.005, Years: 25}
+	years, err := ProjectLifetime(1000, d, 25)
+	if err != nil {
+		t.Fatalf("ProjectLifetime error: %v", err)
+	}
+	if len(years) != 25 {
+		t.Fatalf("expected 25 years, got %d", len(years))
+	}
+	almostEqual(t, years[0].KWh, 990, 1e-9)
+	almostEqual(t, years[1].KWh, 977.5, 1e-9)
+	// Year 25 runs from 86.5% down to 86% of rated output.
+	almostEqual(t, years[24].Factor, 0.8625, 1e-9)
+
+	sum := 0.0
+	for _, y := range years {
+		sum += y.KWh
+	}
+	almostEqual(t, years[24].CumulativeKWh, sum, 1e-6)
+
+	if _, err := ProjectLifetime(1000, d, 0); err == nil {
+		t.Fatal("expected error for zero years")
+	}
+	if _, err := ProjectLifetime(1000, Degradation{FirstYear: 1.5}, 10); err == nil {
+		t.Fatal("expected error for a rate above one")
+	}
+}
+
+func TestBlendDegradation(t *testing.T) {
+	a := Degradation{FirstYear: 0.02, Annual: 0.004, Years: 25}
+	b := Degradation{FirstYear: 0.03, Annual: 0.008, Years: 30}
+	blend := BlendDegradation([]Degradation{a, b}, []float64{3, 1})
+
+	almostEqual(t, blend.Factor(10), (3*a.Factor(10)+b.Factor(10))/4, 1e-12)
+	if blend.Years != 30 {
+		t.Fatalf("expected the longest warranty, got %d", blend.Years)
+	}
+}
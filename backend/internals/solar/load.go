@@ -0,0 +1,285 @@
+package solar
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadProfile is household consumption in Wh for each hour of a typical
+// weekday (index 0) and weekend day (index 1) in every month.
+type LoadProfile struct {
+	Wh [12][2][24]float64 `json:"wh"`
+}
+
+type LoadShape string
+
+const (
+	LoadResidential LoadShape = "residential"
+	LoadDaytime     LoadShape = "daytime"
+	LoadFlat        LoadShape = "flat"
+)
+
+const (
+	DefaultAnnualLoadKWh = 3500
+	maxLoadRows          = 200000
+)
+
+// Hourly weights for weekdays and weekends. Residential follows the usual
+// breakfast and evening peaks with the house empty during the working day;
+// daytime is a household with someone at home.
+var loadShapes = map[LoadShape][2][24]float64{
+	LoadResidential: {
+		{0.4, 0.3, 0.3, 0.3, 0.3, 0.4, 0.8, 1.2, 1.0, 0.6, 0.5, 0.5, 0.6, 0.5, 0.5, 0.6, 0.9, 1.4, 1.8, 1.7, 1.5, 1.2, 0.9, 0.6},
+		{0.4, 0.3, 0.3, 0.3, 0.3, 0.3, 0.5, 0.8, 1.1, 1.2, 1.1, 1.1, 1.2, 1.0, 0.9, 0.9, 1.0, 1.4, 1.7, 1.6, 1.4, 1.2, 0.9, 0.6},
+	},
+	LoadDaytime: {
+		{0.4, 0.3, 0.3, 0.3, 0.3, 0.4, 0.7, 1.0, 1.1, 1.1, 1.0, 1.1, 1.2, 1.1, 1.0, 1.0, 1.1, 1.4, 1.6, 1.5, 1.3, 1.1, 0.8, 0.6},
+		{0.4, 0.3, 0.3, 0.3, 0.3, 0.3, 0.5, 0.8, 1.1, 1.2, 1.1, 1.1, 1.2, 1.0, 0.9, 0.9, 1.0, 1.4, 1.7, 1.6, 1.4, 1.2, 0.9, 0.6},
+	},
+}
+
+// Heating and lighting push winter consumption up.
+var loadSeasonality = [12]float64{1.2, 1.15, 1.05, 0.95, 0.9, 0.85, 0.85, 0.85, 0.9, 1.0, 1.1, 1.2}
+
+func (s LoadShape) Valid() bool {
+	_, ok := loadShapes[s]
+	return ok || s == LoadFlat
+}
+
+// SyntheticLoad scales a standard shape to annualKWh over a non-leap year.
+func SyntheticLoad(shape LoadShape, annualKWh float64) (LoadProfile, error) {
+	if !shape.Valid() {
+		return LoadProfile{}, fmt.Errorf("unknown load profile %q", shape)
+	}
+	if annualKWh <= 0 {
+		annualKWh = DefaultAnnualLoadKWh
+	}
+	weights, ok := loadShapes[shape]
+	seasonal := loadSeasonality
+	if !ok {
+		for d := range weights {
+			for h := range weights[d] {
+				weights[d][h] = 1
+			}
+		}
+		for m := range seasonal {
+			seasonal[m] = 1
+		}
+	}
+
+	var daySums [2]float64
+	for d := range weights {
+		for _, w := range weights[d] {
+			daySums[d] += w
+		}
+	}
+	norm := 0.0
+	for m := range seasonal {
+		days := float64(daysIn(time.Month(m+1), 2025))
+		norm += days * seasonal[m] * (5*daySums[0] + 2*daySums[1]) / 7
+	}
+
+	var l LoadProfile
+	for m := range l.Wh {
+		for d := range l.Wh[m] {
+			for h := range l.Wh[m][d] {
+				l.Wh[m][d][h] = annualKWh * 1000 * seasonal[m] * weights[d][h] / norm
+			}
+		}
+	}
+	return l, nil
+}
+
+func daysIn(m time.Month, year int) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func dayType(t time.Time) int {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return 1
+	}
+	return 0
+}
+
+// LoadWh returns consumption for the hour ending at t.
+func (l LoadProfile) LoadWh(t time.Time) float64 {
+	mid := intervalMidpoint(t)
+	return l.Wh[mid.Month()-1][dayType(mid)][mid.Hour()]
+}
+
+// ParseLoadCSV reads meter data as "timestamp,kWh" rows, where each
+// timestamp starts its interval and sub-hourly readings are summed into
+// hours, or as a bare column of 24 hourly values for a typical day or 8760
+// for a year from 1 January. A header naming "Wh" rather than "kWh" switches
+// the unit. Timestamped data is averaged per month, day type and hour.
+func ParseLoadCSV(r io.Reader) (LoadProfile, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	scale := 1000.0
+	var values []float64
+	hourly := map[time.Time]float64{}
+	rows := 0
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return LoadProfile{}, fmt.Errorf("load csv: %w", err)
+		}
+		if len(rec) == 0 || strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		rows++
+		if rows > maxLoadRows {
+			return LoadProfile{}, fmt.Errorf("load csv: more than %d rows", maxLoadRows)
+		}
+
+		v, err := strconv.ParseFloat(strings.TrimSpace(rec[len(rec)-1]), 64)
+		if err != nil {
+			if rows == 1 {
+				// Header row.
+				unit := strings.ToLower(rec[len(rec)-1])
+				if strings.Contains(unit, "wh") && !strings.Contains(unit, "kwh") {
+					scale = 1
+				}
+				continue
+			}
+			return LoadProfile{}, fmt.Errorf("load csv: row %d: bad value %q", rows, rec[len(rec)-1])
+		}
+		if v < 0 {
+			return LoadProfile{}, fmt.Errorf("load csv: row %d: negative consumption", rows)
+		}
+
+		if len(rec) == 1 {
+			values = append(values, v*scale)
+			continue
+		}
+		ts, err := parseLoadTime(strings.TrimSpace(rec[0]))
+		if err != nil {
+			return LoadProfile{}, fmt.Errorf("load csv: row %d: %w", rows, err)
+		}
+		hourly[ts.Truncate(time.Hour)] += v * scale
+	}
+
+	switch {
+	case len(hourly) > 0:
+		return averageLoad(hourly)
+	case len(values) == 24:
+		var l LoadProfile
+		for m := range l.Wh {
+			for d := range l.Wh[m] {
+				copy(l.Wh[m][d][:], values)
+			}
+		}
+		return l, nil
+	case len(values) == 8760:
+		start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+		for i, v := range values {
+			hourly[start.Add(time.Duration(i)*time.Hour)] = v
+		}
+		return averageLoad(hourly)
+	}
+	return LoadProfile{}, errors.New("load csv: expected timestamped rows or 24 or 8760 values")
+}
+
+func parseLoadTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			// Meter clocks are local; keep the wall time.
+			return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad timestamp %q", s)
+}
+
+// averageLoad fills each cell with the mean of the matching hours. Cells the
+// data does not reach borrow the other day type, then the all-year mean for
+// that hour.
+func averageLoad(hourly map[time.Time]float64) (LoadProfile, error) {
+	var sum, n [12][2][24]float64
+	var hourSum, hourN [24]float64
+	for t, v := range hourly {
+		m, d, h := t.Month()-1, dayType(t), t.Hour()
+		sum[m][d][h] += v
+		n[m][d][h]++
+		hourSum[h] += v
+		hourN[h]++
+	}
+	for h := range hourN {
+		if hourN[h] == 0 {
+			return LoadProfile{}, fmt.Errorf("load csv: no readings for hour %d", h)
+		}
+	}
+
+	var l LoadProfile
+	for m := range l.Wh {
+		for d := range l.Wh[m] {
+			for h := range l.Wh[m][d] {
+				switch {
+				case n[m][d][h] > 0:
+					l.Wh[m][d][h] = sum[m][d][h] / n[m][d][h]
+				case n[m][1-d][h] > 0:
+					l.Wh[m][d][h] = sum[m][1-d][h] / n[m][1-d][h]
+				default:
+					l.Wh[m][d][h] = hourSum[h] / hourN[h]
+				}
+			}
+		}
+	}
+	return l, nil
+}
+
+// LoadSummary compares production with consumption. SelfConsumption is the
+// share of production not exported and SelfSufficiency the share of
+// consumption not imported.
+type LoadSummary struct {
+	LoadWh          float64 `json:"loadWh"`
+	SelfConsumedWh  float64 `json:"selfConsumedWh"`
+	ExportWh        float64 `json:"exportWh"`
+	ImportWh        float64 `json:"importWh"`
+	SelfConsumption float64 `json:"selfConsumption"`
+	SelfSufficiency float64 `json:"selfSufficiency"`
+}
+
+// ApplyLoad nets each hour's EnergyWh against the load and fills the
+// self-consumption and grid flow fields of the points.
+func ApplyLoad(points []HourlyPoint, load LoadProfile) LoadSummary {
+	for i := range points {
+		p := &points[i]
+		p.LoadWh = load.LoadWh(p.Time)
+		p.SelfConsumedWh = math.Min(p.EnergyWh, p.LoadWh)
+		p.ExportWh = p.EnergyWh - p.SelfConsumedWh
+		p.ImportWh = p.LoadWh - p.SelfConsumedWh
+	}
+	return SummarizeLoad(points)
+}
+
+// SummarizeLoad totals the grid flows ApplyLoad, and any battery dispatch
+// after it, left on the points.
+func SummarizeLoad(points []HourlyPoint) LoadSummary {
+	var s LoadSummary
+	production := 0.0
+	for _, p := range points {
+		production += p.EnergyWh
+		s.LoadWh += p.LoadWh
+		s.SelfConsumedWh += p.SelfConsumedWh
+		s.ExportWh += p.ExportWh
+		s.ImportWh += p.ImportWh
+	}
+	if production > 0 {
+		s.SelfConsumption = 1 - s.ExportWh/production
+	}
+	if s.LoadWh > 0 {
+		s.SelfSufficiency = 1 - s.ImportWh/s.LoadWh
+	}
+	return s
+}
@@ -0,0 +1,103 @@
+package solar
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyntheticLoad(t *testing.T) {
+	l, err := SyntheticLoad(LoadResidential, 3650)
+	if err != nil {
+		t.Fatalf("SyntheticLoad error: %v", err)
+	}
+
+	total := 0.0
+	for ts := time.Date(2025, time.January, 1, 1, 0, 0, 0, time.UTC); ts.Year() == 2025; ts = ts.Add(time.Hour) {
+		total += l.LoadWh(ts)
+	}
+	// The hour ending at midnight on 1 January 2026 belongs to 2025.
+	total += l.LoadWh(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC))
+	almostEqual(t, total/1000, 3650, 1)
+
+	if l.Wh[0][0][18] <= l.Wh[6][0][18] {
+		t.Fatal("expected higher winter evening load than summer")
+	}
+	if l.Wh[5][0][18] <= l.Wh[5][0][12] {
+		t.Fatal("expected the evening peak to exceed midday on a weekday")
+	}
+	if _, err := SyntheticLoad("bakery", 1000); err == nil {
+		t.Fatal("expected error for unknown profile")
+	}
+}
+
+func TestParseLoadCSV_Timestamped(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("timestamp,consumption_wh\n")
+	// A Monday and a Saturday at 15-minute resolution.
+	for _, day := range []string{"2025-06-02", "2025-06-07"} {
+		for h := 0; h < 24; h++ {
+			for q := 0; q < 60; q += 15 {
+				v := 100
+				if day == "2025-06-07" {
+					v = 200
+				}
+				fmt.Fprintf(&b, "%s %02d:%02d,%d\n", day, h, q, v)
+			}
+		}
+	}
+
+	l, err := ParseLoadCSV(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("ParseLoadCSV error: %v", err)
+	}
+	almostEqual(t, l.Wh[5][0][10], 400, 1e-9)
+	almostEqual(t, l.Wh[5][1][10], 800, 1e-9)
+	// Other months fall back to the all-data mean for the hour.
+	almostEqual(t, l.Wh[0][0][10], 600, 1e-9)
+}
+
+func TestParseLoadCSV_Day(t *testing.T) {
+	rows := make([]string, 24)
+	for i := range rows {
+		rows[i] = "0.5"
+	}
+	l, err := ParseLoadCSV(strings.NewReader("kWh\n" + strings.Join(rows, "\n")))
+	if err != nil {
+		t.Fatalf("ParseLoadCSV error: %v", err)
+	}
+	almostEqual(t, l.Wh[11][1][3], 500, 1e-9)
+
+	if _, err := ParseLoadCSV(strings.NewReader("1\n2\n3\n")); err == nil {
+		t.Fatal("expected error for a short column")
+	}
+	if _, err := ParseLoadCSV(strings.NewReader("2025-06-02 10:00,-1\n")); err == nil {
+		t.Fatal("expected error for negative consumption")
+	}
+}
+
+func TestApplyLoad(t *testing.T) {
+	var l LoadProfile
+	for m := range l.Wh {
+		for d := range l.Wh[m] {
+			for h := range l.Wh[m][d] {
+				l.Wh[m][d][h] = 500
+			}
+		}
+	}
+	points := []HourlyPoint{
+		{Time: time.Date(2025, time.June, 2, 8, 0, 0, 0, time.UTC), EnergyWh: 200},
+		{Time: time.Date(2025, time.June, 2, 13, 0, 0, 0, time.UTC), EnergyWh: 1500},
+	}
+	s := ApplyLoad(points, l)
+
+	if points[0].SelfConsumedWh != 200 || points[0].ImportWh != 300 || points[0].ExportWh != 0 {
+		t.Fatalf("unexpected morning flows: %+v", points[0])
+	}
+	if points[1].SelfConsumedWh != 500 || points[1].ExportWh != 1000 || points[1].ImportWh != 0 {
+		t.Fatalf("unexpected midday flows: %+v", points[1])
+	}
+	almostEqual(t, s.SelfConsumption, 700.0/1700, 1e-9)
+	almostEqual(t, s.SelfSufficiency, 700.0/1000, 1e-9)
+}
@@ -0,0 +1,221 @@
+package solar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+type ObstacleType string
+
+const (
+	ObstacleBox      ObstacleType = "box"
+	ObstacleCylinder ObstacleType = "cylinder"
+)
+
+// Obstacle is a box (Width along x and Depth along y before Rotation
+// degrees clockwise) or a vertical cylinder, standing on Base. Positions are
+// in a frame centred on the array: x metres east, y metres north, z metres
+// above ground.
+type Obstacle struct {
+	Type     ObstacleType `json:"type"`
+	X        float64      `json:"x"`
+	Y        float64      `json:"y"`
+	Base     float64      `json:"base,omitempty"`
+	Height   float64      `json:"height"`
+	Width    float64      `json:"width,omitempty"`
+	Depth    float64      `json:"depth,omitempty"`
+	Rotation float64      `json:"rotation,omitempty"`
+	Radius   float64      `json:"radius,omitempty"`
+}
+
+// NearShading describes the array as a rectangle Width metres across and
+// Length metres up the slope, centred CenterHeight metres above ground.
+type NearShading struct {
+	Width        float64    `json:"width"`
+	Length       float64    `json:"length"`
+	CenterHeight float64    `json:"centerHeight"`
+	Obstacles    []Obstacle `json:"obstacles"`
+}
+
+const (
+	maxObstacles = 50
+	// shadingGrid points per side are sampled across the array.
+	shadingGrid = 10
+)
+
+func (n NearShading) Validate() error {
+	if n.Width <= 0 || n.Length <= 0 {
+		return errors.New("near shading needs positive array width and length")
+	}
+	if n.CenterHeight < 0 {
+		return errors.New("array height must not be negative")
+	}
+	if len(n.Obstacles) > maxObstacles {
+		return fmt.Errorf("at most %d obstacles", maxObstacles)
+	}
+	for i, o := range n.Obstacles {
+		if o.Height <= 0 {
+			return fmt.Errorf("obstacle %d: height must be positive", i)
+		}
+		switch o.Type {
+		case ObstacleBox:
+			if o.Width <= 0 || o.Depth <= 0 {
+				return fmt.Errorf("obstacle %d: box needs positive width and depth", i)
+			}
+		case ObstacleCylinder:
+			if o.Radius <= 0 {
+				return fmt.Errorf("obstacle %d: cylinder needs a positive radius", i)
+			}
+		default:
+			return fmt.Errorf("obstacle %d: unknown type %q", i, o.Type)
+		}
+	}
+	return nil
+}
+
+type vec3 struct{ x, y, z float64 }
+
+func (a vec3) add(b vec3) vec3      { return vec3{a.x + b.x, a.y + b.y, a.z + b.z} }
+func (a vec3) scale(k float64) vec3 { return vec3{a.x * k, a.y * k, a.z * k} }
+
+func sunVector(sun SunPosition) vec3 {
+	e, a := deg2rad(sun.Elevation), deg2rad(sun.Azimuth)
+	return vec3{math.Sin(a) * math.Cos(e), math.Cos(a) * math.Cos(e), math.Sin(e)}
+}
+
+// ShadedFraction casts a ray towards the sun from a grid of points on the
+// array and returns the share that hit an obstacle.
+func (n NearShading) ShadedFraction(sun SunPosition, arr Array) float64 {
+	if len(n.Obstacles) == 0 || sun.Elevation <= 0 {
+		return 0
+	}
+	dir := sunVector(sun)
+
+	az, tilt := deg2rad(arr.Azimuth), deg2rad(arr.Tilt)
+	across := vec3{math.Cos(az), -math.Sin(az), 0}
+	// Up the slope points away from the direction the array faces.
+	upSlope := vec3{-math.Sin(az) * math.Cos(tilt), -math.Cos(az) * math.Cos(tilt), math.Sin(tilt)}
+	centre := vec3{0, 0, n.CenterHeight}
+
+	hits := 0
+	for i := 0; i < shadingGrid; i++ {
+		a := ((float64(i)+0.5)/shadingGrid - 0.5) * n.Width
+		for j := 0; j < shadingGrid; j++ {
+			b := ((float64(j)+0.5)/shadingGrid - 0.5) * n.Length
+			p := centre.add(across.scale(a)).add(upSlope.scale(b))
+			for _, o := range n.Obstacles {
+				if o.hit(p, dir) {
+					hits++
+					break
+				}
+			}
+		}
+	}
+	return float64(hits) / (shadingGrid * shadingGrid)
+}
+
+// hit reports whether the ray p + t·dir (t > 0) passes through the obstacle.
+// The ray is inside the solid where its horizontal and vertical parameter
+// intervals overlap.
+func (o Obstacle) hit(p, dir vec3) bool {
+	tMin, tMax := 0.0, math.Inf(1)
+
+	var ok bool
+	switch o.Type {
+	case ObstacleBox:
+		// Rotate into the box frame so the slabs are axis-aligned.
+		r := deg2rad(o.Rotation)
+		cos, sin := math.Cos(r), math.Sin(r)
+		px, py := p.x-o.X, p.y-o.Y
+		lx, ly := px*cos-py*sin, px*sin+py*cos
+		dx, dy := dir.x*cos-dir.y*sin, dir.x*sin+dir.y*cos
+		if tMin, tMax, ok = slab(lx, dx, -o.Width/2, o.Width/2, tMin, tMax); !ok {
+			return false
+		}
+		if tMin, tMax, ok = slab(ly, dy, -o.Depth/2, o.Depth/2, tMin, tMax); !ok {
+			return false
+		}
+	case ObstacleCylinder:
+		px, py := p.x-o.X, p.y-o.Y
+		a := dir.x*dir.x + dir.y*dir.y
+		c := px*px + py*py - o.Radius*o.Radius
+		if a < 1e-12 {
+			if c > 0 {
+				return false
+			}
+		} else {
+			b := 2 * (px*dir.x + py*dir.y)
+			disc := b*b - 4*a*c
+			if disc < 0 {
+				return false
+			}
+			sq := math.Sqrt(disc)
+			tMin = math.Max(tMin, (-b-sq)/(2*a))
+			tMax = math.Min(tMax, (-b+sq)/(2*a))
+		}
+	default:
+		return false
+	}
+
+	_, tMax, ok = slab(p.z, dir.z, o.Base, o.Base+o.Height, tMin, tMax)
+	return ok && tMax > 0
+}
+
+// slab narrows [tMin, tMax] to where origin + t·d lies in [lo, hi].
+func slab(origin, d, lo, hi, tMin, tMax float64) (float64, float64, bool) {
+	if math.Abs(d) < 1e-12 {
+		if origin < lo || origin > hi {
+			return tMin, tMax, false
+		}
+		return tMin, tMax, true
+	}
+	t1, t2 := (lo-origin)/d, (hi-origin)/d
+	if t1 > t2 {
+		t1, t2 = t2, t1
+	}
+	tMin, tMax = math.Max(tMin, t1), math.Min(tMax, t2)
+	return tMin, tMax, tMin <= tMax
+}
+
+// geoJSON is the subset of RFC 7946 that ParseObstaclesGeoJSON reads.
+type geoJSON struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Geometry struct {
+			Type        string    `json:"type"`
+			Coordinates []float64 `json:"coordinates"`
+		} `json:"geometry"`
+		Properties Obstacle `json:"properties"`
+	} `json:"features"`
+}
+
+const earthRadius = 6371008.8
+
+// ParseObstaclesGeoJSON reads a FeatureCollection of Point features whose
+// properties carry the obstacle shape (type, height, radius or width/depth,
+// ...). Positions are converted to metres from the array at originLat,
+// originLon.
+func ParseObstaclesGeoJSON(data []byte, originLat, originLon float64) ([]Obstacle, error) {
+	var fc geoJSON
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("geojson: %w", err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, errors.New("geojson: expected a FeatureCollection")
+	}
+
+	out := make([]Obstacle, 0, len(fc.Features))
+	for i, f := range fc.Features {
+		if f.Geometry.Type != "Point" || len(f.Geometry.Coordinates) < 2 {
+			return nil, fmt.Errorf("geojson: feature %d: only Point geometries are supported", i)
+		}
+		lon, lat := f.Geometry.Coordinates[0], f.Geometry.Coordinates[1]
+		o := f.Properties
+		o.X = deg2rad(lon-originLon) * earthRadius * math.Cos(deg2rad(originLat))
+		o.Y = deg2rad(lat-originLat) * earthRadius
+		out = append(out, o)
+	}
+	return out, nil
+}
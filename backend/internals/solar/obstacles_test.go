@@ -0,0 +1,102 @@
+package solar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+func TestShadedFraction(t *testing.T) {
+	flat := Array{Tilt: 0, Azimuth: 180}
+	south := SunPosition{Elevation: 30, Azimuth: 180}
+	north := SunPosition{Elevation: 30, Azimuth: 0}
+	site := func(o ...Obstacle) NearShading {
+		return NearShading{Width: 10, Length: 2, CenterHeight: 1, Obstacles: o}
+	}
+
+	wall := site(Obstacle{Type: ObstacleBox, X: 0, Y: -5, Width: 20, Depth: 1, Height: 100})
+	almostEqual(t, wall.ShadedFraction(south, flat), 1, 1e-9)
+	almostEqual(t, wall.ShadedFraction(north, flat), 0, 1e-9)
+	almostEqual(t, wall.ShadedFraction(SunPosition{Elevation: -5, Azimuth: 180}, flat), 0, 1e-9)
+
+	half := site(Obstacle{Type: ObstacleBox, X: 2.5, Y: -5, Width: 5, Depth: 1, Height: 100})
+	almostEqual(t, half.ShadedFraction(south, flat), 0.5, 1e-9)
+
+	low := site(Obstacle{Type: ObstacleBox, X: 0, Y: -5, Width: 20, Depth: 1, Height: 1.5})
+	almostEqual(t, low.ShadedFraction(SunPosition{Elevation: 60, Azimuth: 180}, flat), 0, 1e-9)
+
+	pole := site(Obstacle{Type: ObstacleCylinder, X: 0, Y: -5, Radius: 1, Height: 100})
+	almostEqual(t, pole.ShadedFraction(south, flat), 0.2, 1e-9)
+
+	turned := site(Obstacle{Type: ObstacleBox, X: 0, Y: -8, Width: 10, Depth: 2, Height: 100, Rotation: 90})
+	almostEqual(t, turned.ShadedFraction(south, flat), 0.2, 1e-9)
+}
+
+func TestNearShadingValidate(t *testing.T) {
+	ok := NearShading{Width: 5, Length: 3, Obstacles: []Obstacle{{Type: ObstacleCylinder, Radius: 1, Height: 4}}}
+	if err := ok.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bad := ok
+	bad.Obstacles = []Obstacle{{Type: "pyramid", Height: 4}}
+	if err := bad.Validate(); err == nil {
+		t.Fatal("expected error for unknown obstacle type")
+	}
+	if err := (NearShading{Length: 3}).Validate(); err == nil {
+		t.Fatal("expected error for missing array width")
+	}
+}
+
+func TestParseObstaclesGeoJSON(t *testing.T) {
+	data := []byte(`{"type":"FeatureCollection","features":[
+{"type":"Feature","geometry":{"type":"Point","coordinates":[10.0,50.0001]},
+ "properties":{"type":"cylinder","radius":3,"height":12}}]}`)
+
+	obs, err := ParseObstaclesGeoJSON(data, 50, 10)
+	if err != nil {
+		t.Fatalf("ParseObstaclesGeoJSON error: %v", err)
+	}
+	if len(obs) != 1 || obs[0].Type != ObstacleCylinder || obs[0].Radius != 3 {
+		t.Fatalf("unexpected obstacles: %+v", obs)
+	}
+	almostEqual(t, obs[0].X, 0, 1e-9)
+	almostEqual(t, obs[0].Y, 11.12, 0.01)
+
+	if _, err := ParseObstaclesGeoJSON([]byte(`{"type":"Feature"}`), 0, 0); err == nil {
+		t.Fatal("expected error for a bare Feature")
+	}
+}
+
+func TestCalculateHourlyOutputForArray_NearShading(t *testing.T) {
+	panel := SolarPanelData{MaximumPowerPmax: 400, TemperatureCoefficientPmax: -0.004, NOCT_Temp: 45}
+	wp := clients.WeatherPack{Hours: []clients.HourWeather{{
+		Time:          time.Date(2025, time.March, 20, 12, 30, 0, 0, time.UTC),
+		AmbientTemp:   10,
+		IrradianceGHI: 500,
+		IrradianceDNI: 700,
+		IrradianceDHI: 100,
+		HasComponents: true,
+	}}}
+	arr := Array{Tilt: 30, Azimuth: 180, Albedo: DefaultAlbedo}
+
+	open, _, _, _, err := CalculateHourlyOutputForArray(panel, wp, 51.5, 0, arr)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	arr.NearShading = &NearShading{Width: 6, Length: 4, CenterHeight: 3, Obstacles: []Obstacle{
+		{Type: ObstacleCylinder, X: 0, Y: -6, Radius: 1.5, Height: 15},
+	}}
+	shaded, _, _, _, err := CalculateHourlyOutputForArray(panel, wp, 51.5, 0, arr)
+	if err != nil {
+		t.Fatalf("shaded: %v", err)
+	}
+
+	p := shaded[0]
+	if p.ShadedFraction <= 0 || p.ShadedFraction >= 1 {
+		t.Fatalf("expected partial shading from a tree to the south, got %.2f", p.ShadedFraction)
+	}
+	if p.EnergyWh >= open[0].EnergyWh || p.ShadingLossWh <= 0 {
+		t.Fatalf("expected a shading loss: open %+v shaded %+v", open[0], p)
+	}
+}
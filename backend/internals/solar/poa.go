@@ -0,0 +1,151 @@
+package solar
+
+import (
+	"math"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+// Array describes how a panel array is mounted. Tilt is degrees from
+// horizontal, Azimuth is degrees clockwise from north (180 = south).
+type Array struct {
+	Tilt          float64            `json:"tilt"`
+	Azimuth       float64            `json:"azimuth"`
+	Albedo        float64            `json:"albedo"`
+	Decomposition DecompositionModel `json:"decomposition,omitempty"`
+	// TemperatureModel defaults to NOCT when empty.
+	TemperatureModel TemperatureModelName `json:"temperatureModel,omitempty"`
+	// IAM and Spectral are off by default.
+	IAM      IAMModel `json:"iam,omitempty"`
+	Spectral bool     `json:"spectral,omitempty"`
+	Horizon  Horizon  `json:"horizon,omitempty"`
+	// NearShading adds obstacles close to the array.
+	NearShading *NearShading `json:"nearShading,omitempty"`
+	// Tracker, when set, replaces Tilt and Azimuth hour by hour.
+	Tracker *Tracker `json:"tracker,omitempty"`
+	// MountingHeight is the height of the module centre above the ground in
+	// metres, used for the rear side of bifacial panels. Zero means
+	// DefaultMountingHeight.
+	MountingHeight float64 `json:"mountingHeight,omitempty"`
+	// SnowStrings is how many bypass-diode substrings are stacked up the
+	// slope: 1 for modules in portrait (the default), 3 for most landscape
+	// layouts. See snowLoss.
+	SnowStrings int `json:"snowStrings,omitempty"`
+	// InitialSnowCoverage is the share of the array under snow when a run
+	// starts, e.g. from SnowCoverageAt over the preceding weather.
+	InitialSnowCoverage float64 `json:"-"`
+}
+
+type POAIrradiance struct {
+	Beam   float64 `json:"beam"`
+	Sky    float64 `json:"sky"`
+	Ground float64 `json:"ground"`
+	Total  float64 `json:"total"`
+	DNI    float64 `json:"dni"`
+	DHI    float64 `json:"dhi"`
+	// Circumsolar is the part of Sky that comes from around the sun's disc.
+	Circumsolar    float64 `json:"circumsolar"`
+	ShadedFraction float64 `json:"shadedFraction,omitempty"`
+	CosAOI         float64 `json:"-"`
+}
+
+const (
+	solarConstant = 1367.0
+	DefaultAlbedo = 0.2
+	maxIrradiance = 1200.0
+	minCosZenith  = 0.01745 // cos(89°), keeps Rb finite near the horizon
+)
+
+func DefaultAzimuth(lat float64) float64 {
+	if lat < 0 {
+		return 0
+	}
+	return 180
+}
+
+func extraterrestrialDNI(ts time.Time) float64 {
+	b := 2 * math.Pi * float64(ts.YearDay()-1) / 365.0
+	return solarConstant * (1.00011 + 0.034221*math.Cos(b) + 0.00128*math.Sin(b) +
+		0.000719*math.Cos(2*b) + 0.000077*math.Sin(2*b))
+}
+
+// TransposeToPOA projects horizontal irradiance onto the array plane using
+// the Hay-Davies sky model and an isotropic ground reflection.
+func TransposeToPOA(ghi, dni, dhi, zenith, sunAzimuth float64, ts time.Time, arr Array) POAIrradiance {
+	tilt := deg2rad(arr.Tilt)
+	zr := deg2rad(zenith)
+
+	cosAOI := math.Cos(zr)*math.Cos(tilt) +
+		math.Sin(zr)*math.Sin(tilt)*math.Cos(deg2rad(sunAzimuth-arr.Azimuth))
+
+	poa := POAIrradiance{DNI: dni, DHI: dhi, CosAOI: cosAOI}
+	if ghi <= 0 {
+		return poa
+	}
+
+	beamCos := math.Max(cosAOI, 0)
+	if zenith >= 90 {
+		beamCos = 0
+	}
+	poa.Beam = dni * beamCos
+
+	ai := 0.0
+	if e := extraterrestrialDNI(ts); e > 0 {
+		ai = clamp01(dni / e)
+	}
+	rb := beamCos / math.Max(math.Cos(zr), minCosZenith)
+	poa.Circumsolar = dhi * ai * rb
+	poa.Sky = poa.Circumsolar + dhi*(1-ai)*(1+math.Cos(tilt))/2
+
+	poa.Ground = ghi * arr.Albedo * (1 - math.Cos(tilt)) / 2
+
+	poa.Total = poa.Beam + poa.Sky + poa.Ground
+	if poa.Total < 0 {
+		poa.Total = 0
+	}
+	return poa
+}
+
+// PlaneOfArray estimates POA irradiance for an hourly weather reading.
+// Open-Meteo reports the mean of the preceding hour, so the sun is placed
+// mid-interval. Supplied DNI/DHI are used as-is; otherwise GHI is decomposed.
+func PlaneOfArray(h clients.HourWeather, lat, lon float64, arr Array) POAIrradiance {
+	mid := intervalMidpoint(h.Time)
+	sun := CalculateSunPosition(mid, lat, lon)
+	return planeOfArrayAt(h, mid, sun, arr.orientedAt(sun))
+}
+
+func intervalMidpoint(ts time.Time) time.Time { return ts.Add(-30 * time.Minute) }
+
+func planeOfArrayAt(h clients.HourWeather, mid time.Time, sun SunPosition, arr Array) POAIrradiance {
+	dni, dhi := h.IrradianceDNI, h.IrradianceDHI
+	if !h.HasComponents {
+		dni, dhi = DecomposeGHI(arr.Decomposition, h.IrradianceGHI, sun.Zenith, mid)
+	}
+	poa := TransposeToPOA(h.IrradianceGHI, dni, dhi, sun.Zenith, sun.Azimuth, mid, arr)
+	switch {
+	case arr.Horizon.Blocks(sun):
+		poa = poa.shadeBeam(1)
+	case arr.NearShading != nil:
+		if f := arr.NearShading.ShadedFraction(sun, arr); f > 0 {
+			poa = poa.shadeBeam(f)
+		}
+	}
+	return poa
+}
+
+// shadeBeam removes fraction f of the direct beam and circumsolar diffuse,
+// the parts that come from the sun's direction.
+func (p POAIrradiance) shadeBeam(f float64) POAIrradiance {
+	lostBeam, lostCirc := p.Beam*f, p.Circumsolar*f
+	p.Total = math.Max(p.Total-lostBeam-lostCirc, 0)
+	p.Sky -= lostCirc
+	p.Beam -= lostBeam
+	p.Circumsolar -= lostCirc
+	p.ShadedFraction = f
+	return p
+}
+
+func deg2rad(d float64) float64 { return d * math.Pi / 180 }
+func rad2deg(r float64) float64 { return r * 180 / math.Pi }
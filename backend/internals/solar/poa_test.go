@@ -0,0 +1,75 @@
+package solar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+func TestPlaneOfArray_HorizontalMatchesGHI(t *testing.T) {
+	ts := time.Date(2025, time.June, 21, 13, 0, 0, 0, time.UTC)
+	arr := Array{Tilt: 0, Azimuth: 180, Albedo: DefaultAlbedo}
+
+	poa := PlaneOfArray(clients.HourWeather{Time: ts, IrradianceGHI: 700}, 51.5, -0.12, arr)
+	almostEqual(t, poa.Total, 700, 1e-6)
+	almostEqual(t, poa.Ground, 0, 1e-9)
+}
+
+func TestPlaneOfArray_SouthTiltWinterGain(t *testing.T) {
+	ts := time.Date(2025, time.December, 21, 12, 30, 0, 0, time.UTC)
+	arr := Array{Tilt: 40, Azimuth: 180, Albedo: DefaultAlbedo}
+
+	poa := PlaneOfArray(clients.HourWeather{Time: ts, IrradianceGHI: 250}, 51.5, -0.12, arr)
+	if poa.Total <= 250 {
+		t.Fatalf("expected tilted winter POA above GHI, got %.2f", poa.Total)
+	}
+	if poa.Beam <= 0 || poa.Sky <= 0 || poa.Ground <= 0 {
+		t.Fatalf("expected all components positive, got %+v", poa)
+	}
+}
+
+func TestPlaneOfArray_NorthFacingLoses(t *testing.T) {
+	ts := time.Date(2025, time.December, 21, 12, 30, 0, 0, time.UTC)
+	arr := Array{Tilt: 40, Azimuth: 0, Albedo: DefaultAlbedo}
+
+	poa := PlaneOfArray(clients.HourWeather{Time: ts, IrradianceGHI: 250}, 51.5, -0.12, arr)
+	if poa.Beam != 0 {
+		t.Fatalf("expected no beam on north-facing plane, got %.2f", poa.Beam)
+	}
+	if poa.Total >= 250 {
+		t.Fatalf("expected north-facing POA below GHI, got %.2f", poa.Total)
+	}
+}
+
+func TestPlaneOfArray_UsesSuppliedComponents(t *testing.T) {
+	ts := time.Date(2025, time.June, 21, 13, 0, 0, 0, time.UTC)
+	arr := Array{Tilt: 30, Azimuth: 180, Albedo: DefaultAlbedo}
+	h := clients.HourWeather{
+		Time:          ts,
+		IrradianceGHI: 600,
+		IrradianceDNI: 0,
+		IrradianceDHI: 600,
+		HasComponents: true,
+	}
+
+	poa := PlaneOfArray(h, 51.5, -0.12, arr)
+	if poa.Beam != 0 {
+		t.Fatalf("expected zero beam with supplied DNI of 0, got %.2f", poa.Beam)
+	}
+	almostEqual(t, poa.DHI, 600, 1e-9)
+}
+
+func TestCalculateHourlyOutputForArray_SymmetricFixedBand(t *testing.T) {
+	panel := SolarPanelData{MaximumPowerPmax: 400, TemperatureCoefficientPmax: -0.004, NOCT_Temp: 45}
+	ts := time.Date(2025, time.March, 21, 13, 0, 0, 0, time.UTC)
+	wp := clients.WeatherPack{Hours: []clients.HourWeather{{Time: ts, AmbientTemp: 10, IrradianceGHI: 500}}}
+
+	points, _, _, _, err := CalculateHourlyOutputForArray(panel, wp, 51.5, -0.12, Array{Tilt: 35, Azimuth: 180})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := points[0]
+	almostEqual(t, p.EnergyWhLow, p.EnergyWh*(1-fixedBandUncertainty), 1e-9)
+	almostEqual(t, p.EnergyWhHigh, p.EnergyWh*(1+fixedBandUncertainty), 1e-9)
+}
@@ -0,0 +1,67 @@
+package solar
+
+import (
+	"testing"
+	"time"
+)
+
+// Reference values from the SPA report (Reda & Andreas, NREL/TP-560-34302).
+// The report fixes ΔT at 67 s; our polynomial gives ~64.5 s, which moves the
+// azimuth by a few 1e-5 degrees.
+func TestCalculateSunPositionFor_SPAReference(t *testing.T) {
+	ts := time.Date(2003, time.October, 17, 12, 30, 30, 0, time.FixedZone("MST", -7*3600))
+	obs := Observer{
+		Lat:         39.742476,
+		Lon:         -105.1786,
+		Elevation:   1830.14,
+		Pressure:    820,
+		Temperature: 11,
+	}
+
+	pos := CalculateSunPositionFor(ts, obs)
+	almostEqual(t, pos.Zenith, 50.11162, 1e-4)
+	almostEqual(t, pos.Azimuth, 194.34024, 1e-4)
+}
+
+func TestCalculateSunTimes_SPAReference(t *testing.T) {
+	loc := time.FixedZone("MST", -7*3600)
+	day := time.Date(2003, time.October, 17, 0, 0, 0, 0, loc)
+
+	st := CalculateSunTimes(day, 39.742476, -105.1786)
+	if st.PolarDay || st.PolarNight {
+		t.Fatalf("unexpected polar flags: %+v", st)
+	}
+
+	want := map[string]time.Time{
+		"sunrise": time.Date(2003, time.October, 17, 6, 12, 43, 0, loc),
+		"noon":    time.Date(2003, time.October, 17, 11, 46, 4, 0, loc),
+		"sunset":  time.Date(2003, time.October, 17, 17, 20, 19, 0, loc),
+	}
+	got := map[string]time.Time{"sunrise": st.Sunrise, "noon": st.SolarNoon, "sunset": st.Sunset}
+	for k, w := range want {
+		if d := got[k].Sub(w); d < -2*time.Second || d > 2*time.Second {
+			t.Fatalf("%s: got %s, want %s", k, got[k].Format(time.RFC3339), w.Format(time.RFC3339))
+		}
+	}
+}
+
+func TestCalculateSunTimes_Polar(t *testing.T) {
+	summer := CalculateSunTimes(time.Date(2025, time.June, 21, 0, 0, 0, 0, time.UTC), 78.2, 15.6)
+	if !summer.PolarDay {
+		t.Fatalf("expected polar day at Svalbard in June, got %+v", summer)
+	}
+	winter := CalculateSunTimes(time.Date(2025, time.December, 21, 0, 0, 0, 0, time.UTC), 78.2, 15.6)
+	if !winter.PolarNight {
+		t.Fatalf("expected polar night at Svalbard in December, got %+v", winter)
+	}
+}
+
+func TestCalculateSunPosition_SouthernNoon(t *testing.T) {
+	// Sydney, local solar noon in January: the sun is to the north.
+	loc := time.FixedZone("AEDT", 11*3600)
+	st := CalculateSunTimes(time.Date(2025, time.January, 15, 0, 0, 0, 0, loc), -33.87, 151.21)
+	pos := CalculateSunPosition(st.SolarNoon, -33.87, 151.21)
+	if pos.Azimuth > 5 && pos.Azimuth < 355 {
+		t.Fatalf("expected northern azimuth at noon, got %.3f", pos.Azimuth)
+	}
+}
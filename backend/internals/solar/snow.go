@@ -0,0 +1,115 @@
+package solar
+
+import (
+	"math"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+// Coefficients of the Marion et al. (2013) snow model.
+const (
+	// snowfallThreshold is the fresh snow in cm per hour that buries the
+	// array again.
+	snowfallThreshold = 1.0
+	// SnowDepthThreshold is the ground cover in cm below which the panels
+	// are taken to be clear.
+	SnowDepthThreshold = 1.0
+	// Snow can slide once the air temperature exceeds POA/snowSlideLimit
+	// (°C per W/m²), so strong sun lets it go below freezing.
+	snowSlideLimit = -80.0
+	// snowSlideRate is the share of the module that slides off per hour,
+	// scaled by the sine of the tilt.
+	snowSlideRate = 0.197
+	// snowMeltRate is the share of the module that melts clear per hour and
+	// degree of warmth above the slide limit. It only applies without depth
+	// readings, when bare ground cannot tell that the snow has gone, and is
+	// what clears flat arrays, which never shed snow by sliding.
+	snowMeltRate = 0.1
+)
+
+// SnowOnGround reports whether the hour has enough snow lying to cover a
+// freshly buried array.
+func SnowOnGround(h clients.HourWeather) bool {
+	return h.HasSnowDepth && h.SnowDepth >= SnowDepthThreshold
+}
+
+// snowCover tracks the share of the array under snow from hour to hour.
+type snowCover struct {
+	coverage  float64
+	prevDepth float64
+	hasPrev   bool
+}
+
+func newSnowCover(initial float64) *snowCover {
+	return &snowCover{coverage: clamp01(initial)}
+}
+
+// step advances the coverage over one hour with the given plane-of-array
+// irradiance and surface tilt. A snowfall, or a rise in depth where only
+// depth is known, buries the array; otherwise snow slides off when it is
+// warm enough, and it is gone once the ground is clear. Without a depth
+// reading it also melts in proportion to the warmth.
+func (s *snowCover) step(h clients.HourWeather, poa, tilt float64) float64 {
+	fresh := h.Snowfall
+	if h.HasSnowDepth {
+		if s.hasPrev {
+			fresh = math.Max(fresh, h.SnowDepth-s.prevDepth)
+		}
+		s.prevDepth, s.hasPrev = h.SnowDepth, true
+	}
+
+	switch {
+	case fresh >= snowfallThreshold:
+		s.coverage = 1
+	case h.HasSnowDepth && h.SnowDepth < SnowDepthThreshold:
+		s.coverage = 0
+	case s.coverage > 0:
+		// Degrees above the temperature at which snow starts to move.
+		warmth := h.AmbientTemp - poa/snowSlideLimit
+		if warmth <= 0 {
+			break
+		}
+		loss := snowSlideRate * math.Sin(deg2rad(tilt))
+		if !h.HasSnowDepth {
+			loss += snowMeltRate * warmth
+		}
+		s.coverage = math.Max(s.coverage-loss, 0)
+	}
+	return s.coverage
+}
+
+// snowLoss is the share of output lost to coverage. Any snow on a
+// substring's lower edge makes its bypass diode drop the whole substring, so
+// loss comes in steps of 1/strings (Marion's portrait case is one string).
+func snowLoss(coverage float64, strings int) float64 {
+	if coverage <= 0 {
+		return 0
+	}
+	if strings < 1 {
+		strings = 1
+	}
+	return math.Min(math.Ceil(coverage*float64(strings))/float64(strings), 1)
+}
+
+// SnowCoverageAt runs the snow model over the hours of wp stamped before t,
+// starting from arr.InitialSnowCoverage, and returns the coverage at t. It
+// lets a run that starts at t carry on from the weather before it.
+func SnowCoverageAt(wp clients.WeatherPack, lat, lon float64, arr Array, t time.Time) float64 {
+	snow := newSnowCover(arr.InitialSnowCoverage)
+	for _, h := range wp.Hours {
+		if !h.Time.Before(t) {
+			break
+		}
+		poa, tilt := 0.0, arr.Tilt
+		// Irradiance only matters while there is snow to slide.
+		if snow.coverage > 0 {
+			mid := intervalMidpoint(h.Time)
+			sun := CalculateSunPosition(mid, lat, lon)
+			hourArr := arr.orientedAt(sun)
+			poa, tilt = planeOfArrayAt(h, mid, sun, hourArr).Total, hourArr.Tilt
+		}
+		snow.step(h, poa, tilt)
+	}
+	return snow.coverage
+}
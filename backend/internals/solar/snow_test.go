@@ -0,0 +1,122 @@
+package solar
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+func TestSnowLoss(t *testing.T) {
+	almostEqual(t, snowLoss(0, 0), 0, 1e-12)
+	almostEqual(t, snowLoss(0.05, 0), 1, 1e-12)
+	almostEqual(t, snowLoss(0.05, 3), 1.0/3, 1e-12)
+	almostEqual(t, snowLoss(0.5, 3), 2.0/3, 1e-12)
+	almostEqual(t, snowLoss(1, 3), 1, 1e-12)
+}
+
+func TestSnowCoverStep(t *testing.T) {
+	s := newSnowCover(0)
+	snowing := clients.HourWeather{AmbientTemp: -2, Snowfall: 2, SnowDepth: 10, HasSnowDepth: true}
+	almostEqual(t, s.step(snowing, 0, 40), 1, 1e-12)
+
+	// Cold and dark: the snow stays put.
+	cold := clients.HourWeather{AmbientTemp: -5, SnowDepth: 10, HasSnowDepth: true}
+	almostEqual(t, s.step(cold, 0, 40), 1, 1e-12)
+
+	// Bright sun lets it slide below freezing: -5 > -400/80.
+	almostEqual(t, s.step(cold, 500, 40), 1-snowSlideRate*math.Sin(deg2rad(40)), 1e-12)
+
+	// A rise in depth buries the array when snowfall is not reported.
+	almostEqual(t, s.step(clients.HourWeather{AmbientTemp: -5, SnowDepth: 12, HasSnowDepth: true}, 0, 40), 1, 1e-12)
+
+	// Once the ground is bare the panels are too.
+	almostEqual(t, s.step(clients.HourWeather{AmbientTemp: 5, HasSnowDepth: true}, 0, 40), 0, 1e-12)
+
+	// Flat panels never shed snow by sliding.
+	flat := newSnowCover(1)
+	almostEqual(t, flat.step(clients.HourWeather{AmbientTemp: 5, SnowDepth: 10, HasSnowDepth: true}, 800, 0), 1, 1e-12)
+}
+
+func TestSnowCoverStep_FlatWithoutDepth(t *testing.T) {
+	// Ensemble members and TMY3 files carry snowfall but no depth, so only
+	// melting can clear a flat array.
+	s := newSnowCover(0)
+	almostEqual(t, s.step(clients.HourWeather{AmbientTemp: -2, Snowfall: 2}, 0, 0), 1, 1e-12)
+
+	// Cold and dark: nothing melts.
+	almostEqual(t, s.step(clients.HourWeather{AmbientTemp: -5}, 0, 0), 1, 1e-12)
+
+	// Mild air melts it a share per degree: 2 °C plus 160/80 from the sun.
+	almostEqual(t, s.step(clients.HourWeather{AmbientTemp: 2}, 160, 0), 1-4*snowMeltRate, 1e-12)
+
+	hours := 0
+	for i := 0; i < 24 && s.coverage > 0; i++ {
+		s.step(clients.HourWeather{AmbientTemp: 3}, 0, 0)
+		hours = i + 1
+	}
+	if s.coverage != 0 || hours > 3 {
+		t.Fatalf("expected a mild day to clear the flat array within hours, coverage %.2f after %d h", s.coverage, hours)
+	}
+}
+
+// snowyDays is two winter days with a snowfall before dawn on the first and
+// snow on the ground throughout.
+func snowyDays() clients.WeatherPack {
+	var wp clients.WeatherPack
+	start := time.Date(2025, time.February, 10, 1, 0, 0, 0, time.UTC)
+	for i := 0; i < 48; i++ {
+		ts := start.Add(time.Duration(i) * time.Hour)
+		h := clients.HourWeather{Time: ts, AmbientTemp: -3, SnowDepth: 20, HasSnowDepth: true}
+		if hr := ts.Hour(); hr >= 9 && hr <= 15 {
+			h.IrradianceGHI = 300
+			h.AmbientTemp = 1
+		}
+		if i == 3 {
+			h.Snowfall = 5
+		}
+		wp.Hours = append(wp.Hours, h)
+	}
+	return wp
+}
+
+func TestCalculateHourlyOutputForArray_Snow(t *testing.T) {
+	panel := SolarPanelData{MaximumPowerPmax: 400, TemperatureCoefficientPmax: -0.0035, NOCT_Temp: 45}
+	arr := Array{Tilt: 40, Azimuth: 180, Albedo: DefaultAlbedo}
+	wp := snowyDays()
+
+	points, total, _, _, err := CalculateHourlyOutputForArray(panel, wp, 60, 10, arr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lossWh float64
+	for _, p := range points[:12] {
+		if p.SnowCoverage > 0 && p.EnergyWh != 0 {
+			t.Fatalf("expected no output under snow at %s: %+v", p.Time, p)
+		}
+		lossWh += p.SnowLossWh
+	}
+	if lossWh <= 0 {
+		t.Fatal("expected snow to cost output on the first morning")
+	}
+	last := points[len(points)-1]
+	if last.SnowCoverage != 0 || total <= 0 {
+		t.Fatalf("expected the snow to slide off by the second day, coverage %.2f, total %.1f", last.SnowCoverage, total)
+	}
+
+	// Splitting the run at midnight and carrying the coverage across gives
+	// the same second day.
+	midnight := time.Date(2025, time.February, 11, 1, 0, 0, 0, time.UTC)
+	split := arr
+	split.InitialSnowCoverage = SnowCoverageAt(wp, 60, 10, arr, midnight)
+	second := clients.WeatherPack{Hours: wp.Hours[24:]}
+	day2, _, _, _, err := CalculateHourlyOutputForArray(panel, second, 60, 10, split)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range day2 {
+		almostEqual(t, p.SnowCoverage, points[24+i].SnowCoverage, 1e-12)
+		almostEqual(t, p.EnergyWh, points[24+i].EnergyWh, 1e-9)
+	}
+}
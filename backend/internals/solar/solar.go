@@ -10,6 +10,22 @@ type SolarPanelData struct {
 	ModelNo                    string  `json:"model_no"`
 	TemperatureCoefficientPmax float64 `json:"temperature_coefficient_pmax"`
 	MaximumPowerPmax           float64 `json:"maximum_power_pmax"`
+	// Degradation is a fraction of rated power; zero means not published.
+	FirstYearDegradation float64 `json:"first_year_degradation,omitempty"`
+	AnnualDegradation    float64 `json:"annual_degradation,omitempty"`
+	WarrantyYears        int     `json:"warranty_years,omitempty"`
+	// Bifaciality is the rear-to-front efficiency ratio; zero for
+	// monofacial panels.
+	Bifaciality float64 `json:"bifaciality,omitempty"`
+}
+
+func DefaultPanelData() map[string]SolarPanelData {
+	return map[string]SolarPanelData{
+		"Mono-Default-400": {ModelNo: "Mono-Default-400", MaximumPowerPmax: 400, TemperatureCoefficientPmax: -0.0035, NOCT_Temp: 45, FirstYearDegradation: 0.02, AnnualDegradation: 0.0055, WarrantyYears: 25},
+		"Poly-Default-340": {ModelNo: "Poly-Default-340", MaximumPowerPmax: 340, TemperatureCoefficientPmax: -0.0040, NOCT_Temp: 45, FirstYearDegradation: 0.025, AnnualDegradation: 0.007, WarrantyYears: 25},
+		"Thin-Default-150": {ModelNo: "Thin-Default-150", MaximumPowerPmax: 150, TemperatureCoefficientPmax: -0.0025, NOCT_Temp: 47, FirstYearDegradation: 0.03, AnnualDegradation: 0.007, WarrantyYears: 25},
+		"Bifi-Default-430": {ModelNo: "Bifi-Default-430", MaximumPowerPmax: 430, TemperatureCoefficientPmax: -0.0030, NOCT_Temp: 44, FirstYearDegradation: 0.01, AnnualDegradation: 0.004, WarrantyYears: 30, Bifaciality: 0.7},
+	}
 }
 
 func LoadSolarPanelData() (map[string]SolarPanelData, error) {
@@ -0,0 +1,186 @@
+package solar
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+// SubArray is one orientation group on a site, e.g. the east face of a split
+// roof. Data carries the resolved panel spec for Panel.
+type SubArray struct {
+	Name  string         `json:"name,omitempty"`
+	Panel string         `json:"panel"`
+	Count int            `json:"count"`
+	Array Array          `json:"array"`
+	Data  SolarPanelData `json:"-"`
+}
+
+type SubArrayResult struct {
+	Name    string        `json:"name,omitempty"`
+	Panel   string        `json:"panel"`
+	Count   int           `json:"count"`
+	Tilt    float64       `json:"tilt"`
+	Azimuth float64       `json:"azimuth"`
+	RatingW float64       `json:"ratingW"`
+	TotalWh float64       `json:"totalWh"`
+	Points  []HourlyPoint `json:"points"`
+}
+
+// CalculateSubArrays models each sub-array against the same weather and sums
+// them into a combined DC curve. lossFactor is applied to every sub-array;
+// pass 1 when no system losses are modelled. The combined POA and RearPOA
+// are rating-weighted means across sub-arrays.
+func CalculateSubArrays(
+	subs []SubArray,
+	wp clients.WeatherPack,
+	lat, lon, lossFactor float64,
+) ([]SubArrayResult, []HourlyPoint, error) {
+	if len(subs) == 0 {
+		return nil, nil, errors.New("no sub-arrays")
+	}
+
+	results := make([]SubArrayResult, 0, len(subs))
+	var combined []HourlyPoint
+	var totalRating float64
+
+	for i, sub := range subs {
+		points, _, _, _, err := CalculateHourlyOutputForArray(sub.Data, wp, lat, lon, sub.Array)
+		if err != nil {
+			return nil, nil, fmt.Errorf("array %d: %w", i, err)
+		}
+		points = ScalePoints(points, float64(sub.Count)*lossFactor)
+		rating := sub.Data.MaximumPowerPmax * float64(sub.Count)
+		total, _, _ := Totals(points)
+
+		results = append(results, SubArrayResult{
+			Name:    sub.Name,
+			Panel:   sub.Panel,
+			Count:   sub.Count,
+			Tilt:    sub.Array.Tilt,
+			Azimuth: sub.Array.Azimuth,
+			RatingW: rating,
+			TotalWh: total,
+			Points:  points,
+		})
+
+		if combined == nil {
+			combined = make([]HourlyPoint, len(points))
+			for j, p := range points {
+				combined[j] = HourlyPoint{
+					Time: p.Time, Ambient: p.Ambient, GHI: p.GHI, DNI: p.DNI, DHI: p.DHI, Wind: p.Wind,
+					ClearSkyGHI: p.ClearSkyGHI, ClearSkyIndex: p.ClearSkyIndex,
+				}
+			}
+		}
+		for j, p := range points {
+			c := &combined[j]
+			c.POA += p.POA * rating
+			c.RearPOA += p.RearPOA * rating
+			c.ShadedFraction += p.ShadedFraction * rating
+			c.SnowCoverage += p.SnowCoverage * rating
+			c.EnergyWh += p.EnergyWh
+			c.EnergyWhLow += p.EnergyWhLow
+			c.EnergyWhHigh += p.EnergyWhHigh
+			c.DCWh += p.DCWh
+			c.ClearSkyWh += p.ClearSkyWh
+			c.ShadingLossWh += p.ShadingLossWh
+			c.BifacialGainWh += p.BifacialGainWh
+			c.SnowLossWh += p.SnowLossWh
+		}
+		totalRating += rating
+	}
+
+	var cum, cumLow, cumHigh float64
+	for j := range combined {
+		c := &combined[j]
+		if totalRating > 0 {
+			c.POA /= totalRating
+			c.RearPOA /= totalRating
+			c.ShadedFraction /= totalRating
+			c.SnowCoverage /= totalRating
+		}
+		cum += c.EnergyWh
+		cumLow += c.EnergyWhLow
+		cumHigh += c.EnergyWhHigh
+		c.CumulativeWh = cum
+		c.CumulativeLow = cumLow
+		c.CumulativeHigh = cumHigh
+	}
+	return results, combined, nil
+}
+
+// ShareCombined splits each hour of the combined curve back across the
+// sub-arrays in proportion to their own output that hour, so that per-array
+// curves stay in the same units as the combined one once it has been through
+// the inverter (AC, with clipping shared out too). Each array's DCWh keeps
+// its own DC energy. Per-array cumulatives are the combined cumulatives
+// split by each array's running share, so that with ensemble bands, where the
+// combined cumulatives are daily quantiles, the array totals still add up to
+// the combined total.
+func ShareCombined(arrays []SubArrayResult, combined []HourlyPoint) {
+	share := func(part, whole, total float64) float64 {
+		if whole <= 0 {
+			return 0
+		}
+		return total * part / whole
+	}
+	for j, c := range combined {
+		var dc HourlyPoint
+		for _, a := range arrays {
+			p := a.Points[j]
+			dc.EnergyWh += p.EnergyWh
+			dc.EnergyWhLow += p.EnergyWhLow
+			dc.EnergyWhHigh += p.EnergyWhHigh
+			dc.ClearSkyWh += p.ClearSkyWh
+			dc.ShadingLossWh += p.ShadingLossWh
+			dc.BifacialGainWh += p.BifacialGainWh
+			dc.SnowLossWh += p.SnowLossWh
+		}
+		for a := range arrays {
+			p := &arrays[a].Points[j]
+			energy := p.EnergyWh
+			p.DCWh = energy
+			p.EnergyWh = share(energy, dc.EnergyWh, c.EnergyWh)
+			p.EnergyWhLow = share(p.EnergyWhLow, dc.EnergyWhLow, c.EnergyWhLow)
+			p.EnergyWhHigh = share(p.EnergyWhHigh, dc.EnergyWhHigh, c.EnergyWhHigh)
+			p.ClearSkyWh = share(p.ClearSkyWh, dc.ClearSkyWh, c.ClearSkyWh)
+			p.ShadingLossWh = share(p.ShadingLossWh, dc.ShadingLossWh, c.ShadingLossWh)
+			p.BifacialGainWh = share(p.BifacialGainWh, dc.BifacialGainWh, c.BifacialGainWh)
+			p.SnowLossWh = share(p.SnowLossWh, dc.SnowLossWh, c.SnowLossWh)
+			p.ClippedWh = share(energy, dc.EnergyWh, c.ClippedWh)
+			p.CurtailedWh = share(energy, dc.EnergyWh, c.CurtailedWh)
+		}
+	}
+	running := make([]HourlyPoint, len(arrays))
+	for j, c := range combined {
+		var sum HourlyPoint
+		for a := range arrays {
+			p := arrays[a].Points[j]
+			running[a].EnergyWh += p.EnergyWh
+			running[a].EnergyWhLow += p.EnergyWhLow
+			running[a].EnergyWhHigh += p.EnergyWhHigh
+			sum.EnergyWh += running[a].EnergyWh
+			sum.EnergyWhLow += running[a].EnergyWhLow
+			sum.EnergyWhHigh += running[a].EnergyWhHigh
+		}
+		for a := range arrays {
+			p := &arrays[a].Points[j]
+			p.CumulativeWh = share(running[a].EnergyWh, sum.EnergyWh, c.CumulativeWh)
+			p.CumulativeLow = share(running[a].EnergyWhLow, sum.EnergyWhLow, c.CumulativeLow)
+			p.CumulativeHigh = share(running[a].EnergyWhHigh, sum.EnergyWhHigh, c.CumulativeHigh)
+		}
+	}
+	for a := range arrays {
+		arrays[a].TotalWh, _, _ = Totals(arrays[a].Points)
+	}
+}
+
+func SubArrayRating(subs []SubArray) (ratingW float64, modules int) {
+	for _, s := range subs {
+		ratingW += s.Data.MaximumPowerPmax * float64(s.Count)
+		modules += s.Count
+	}
+	return ratingW, modules
+}
@@ -0,0 +1,99 @@
+package solar
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+func TestCalculateSubArrays_EastWestSplit(t *testing.T) {
+	var wp clients.WeatherPack
+	day := time.Date(2025, time.June, 21, 0, 0, 0, 0, time.UTC)
+	for h := 1; h <= 24; h++ {
+		ghi := math.Max(0, 800*math.Sin(math.Pi*float64(h-5)/16))
+		wp.Hours = append(wp.Hours, clients.HourWeather{Time: day.Add(time.Duration(h) * time.Hour), AmbientTemp: 20, IrradianceGHI: ghi})
+	}
+	panel := SolarPanelData{MaximumPowerPmax: 400, TemperatureCoefficientPmax: -0.0035, NOCT_Temp: 45}
+	subs := []SubArray{
+		{Name: "east", Panel: "p", Count: 6, Array: Array{Tilt: 30, Azimuth: 90, Albedo: DefaultAlbedo}, Data: panel},
+		{Name: "west", Panel: "p", Count: 4, Array: Array{Tilt: 30, Azimuth: 270, Albedo: DefaultAlbedo}, Data: panel},
+	}
+
+	results, combined, err := CalculateSubArrays(subs, wp, 51.5, 0, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || len(combined) != 24 {
+		t.Fatalf("unexpected shapes: %d results, %d points", len(results), len(combined))
+	}
+
+	east, west := results[0].Points, results[1].Points
+	if east[7].EnergyWh/6 <= west[7].EnergyWh/4 {
+		t.Fatal("expected east array to lead per module in the morning")
+	}
+	if east[18].EnergyWh/6 >= west[18].EnergyWh/4 {
+		t.Fatal("expected west array to lead per module in the evening")
+	}
+
+	total, _, _ := Totals(combined)
+	almostEqual(t, total, results[0].TotalWh+results[1].TotalWh, 1e-6)
+
+	rating, modules := SubArrayRating(subs)
+	almostEqual(t, rating, 4000, 1e-9)
+	if modules != 10 {
+		t.Fatalf("expected 10 modules, got %d", modules)
+	}
+}
+
+func TestShareCombined_FollowsInverter(t *testing.T) {
+	ts := time.Date(2025, time.June, 21, 13, 0, 0, 0, time.UTC)
+	arrays := []SubArrayResult{
+		{Points: []HourlyPoint{{Time: ts, EnergyWh: 3000, EnergyWhLow: 2700, EnergyWhHigh: 3300}}},
+		{Points: []HourlyPoint{{Time: ts, EnergyWh: 1000, EnergyWhLow: 900, EnergyWhHigh: 1100}}},
+	}
+	sys := System{Inverter: Inverter{ACRatingW: 3000}}
+	combined, _ := sys.ConvertDC([]HourlyPoint{{Time: ts, EnergyWh: 4000, EnergyWhLow: 3600, EnergyWhHigh: 4400}}, 4000, 10)
+
+	ShareCombined(arrays, combined)
+	c := combined[0]
+	almostEqual(t, arrays[0].TotalWh+arrays[1].TotalWh, c.EnergyWh, 1e-9)
+	almostEqual(t, arrays[0].Points[0].EnergyWh, c.EnergyWh*0.75, 1e-9)
+	almostEqual(t, arrays[0].Points[0].ClippedWh+arrays[1].Points[0].ClippedWh, c.ClippedWh, 1e-9)
+	almostEqual(t, arrays[1].Points[0].DCWh, 1000, 1e-9)
+	almostEqual(t, arrays[1].Points[0].CumulativeHigh, c.EnergyWhHigh*0.25, 1e-9)
+}
+
+func TestShareCombined_TotalsMatchDailyQuantile(t *testing.T) {
+	ts := time.Date(2025, time.June, 21, 12, 0, 0, 0, time.UTC)
+	arrays := []SubArrayResult{
+		{Points: []HourlyPoint{{Time: ts, EnergyWh: 300}, {Time: ts.Add(time.Hour), EnergyWh: 100}}},
+		{Points: []HourlyPoint{{Time: ts, EnergyWh: 100}, {Time: ts.Add(time.Hour), EnergyWh: 100}}},
+	}
+	// The ensemble's daily P50 is not the sum of its hourly P50s.
+	combined := []HourlyPoint{
+		{Time: ts, EnergyWh: 400, CumulativeWh: 400},
+		{Time: ts.Add(time.Hour), EnergyWh: 200, CumulativeWh: 540},
+	}
+
+	ShareCombined(arrays, combined)
+	almostEqual(t, arrays[0].TotalWh+arrays[1].TotalWh, 540, 1e-9)
+	almostEqual(t, arrays[0].TotalWh, 540*4.0/6, 1e-9)
+}
+
+func TestShareCombined_Curtailment(t *testing.T) {
+	ts := time.Date(2025, time.June, 21, 13, 0, 0, 0, time.UTC)
+	arrays := []SubArrayResult{
+		{Points: []HourlyPoint{{Time: ts, EnergyWh: 2000}}},
+		{Points: []HourlyPoint{{Time: ts, EnergyWh: 2000}}},
+	}
+	combined := []HourlyPoint{{Time: ts, EnergyWh: 4000, CumulativeWh: 4000}}
+	ApplyExportLimit(combined, 3000, false)
+
+	ShareCombined(arrays, combined)
+	for _, a := range arrays {
+		almostEqual(t, a.Points[0].EnergyWh, 1500, 1e-9)
+		almostEqual(t, a.Points[0].CurtailedWh, 500, 1e-9)
+	}
+}
@@ -0,0 +1,299 @@
+package solar
+
+import (
+	"errors"
+	"math"
+)
+
+// System scales a single-module estimate up to a full installation:
+// ModulesPerString x Strings modules feeding InverterCount identical inverters.
+type System struct {
+	ModulesPerString int      `json:"modulesPerString"`
+	Strings          int      `json:"strings"`
+	InverterCount    int      `json:"inverterCount,omitempty"`
+	Inverter         Inverter `json:"inverter"`
+	Losses           *Losses  `json:"losses,omitempty"`
+	// ExportLimitW caps power fed to the grid; zero is a valid limit, so nil
+	// means uncapped.
+	ExportLimitW *float64 `json:"exportLimitW,omitempty"`
+}
+
+// Inverter uses the Sandia model when Spec is set; otherwise the PVWatts
+// part-load efficiency curve around a nominal efficiency. AC output is
+// clipped at the nameplate rating. Spec is only ever resolved server-side
+// from Model against the imported CEC list, never taken from a request.
+type Inverter struct {
+	Model      string        `json:"model,omitempty"`
+	ACRatingW  float64       `json:"acRatingW"`
+	Efficiency float64       `json:"efficiency,omitempty"`
+	Spec       *InverterData `json:"-"`
+}
+
+// Losses are fractional DC-side derates applied multiplicatively.
+type Losses struct {
+	Wiring   float64 `json:"wiring"`
+	Mismatch float64 `json:"mismatch"`
+	Soiling  float64 `json:"soiling"`
+	Other    float64 `json:"other,omitempty"`
+}
+
+type SystemSummary struct {
+	Modules       int     `json:"modules"`
+	DCRatingW     float64 `json:"dcRatingW"`
+	ACRatingW     float64 `json:"acRatingW"`
+	DCACRatio     float64 `json:"dcAcRatio"`
+	LossFactor    float64 `json:"lossFactor"`
+	DCWh          float64 `json:"dcWh"`
+	ACWh          float64 `json:"acWh"`
+	ClippedWh     float64 `json:"clippedWh"`
+	ClippingHours int     `json:"clippingHours"`
+	CurtailedWh   float64 `json:"curtailedWh,omitempty"`
+}
+
+const (
+	defaultInverterEfficiency = 0.96
+	pvwattsReferenceEff       = 0.9637
+)
+
+func DefaultLosses() Losses {
+	return Losses{Wiring: 0.02, Mismatch: 0.02, Soiling: 0.02}
+}
+
+func (l Losses) Factor() float64 {
+	return (1 - l.Wiring) * (1 - l.Mismatch) * (1 - l.Soiling) * (1 - l.Other)
+}
+
+// losses falls back to DefaultLosses when the caller gave none.
+func (s System) losses() Losses {
+	if s.Losses == nil {
+		return DefaultLosses()
+	}
+	return *s.Losses
+}
+
+func (s System) Modules() int {
+	return s.ModulesPerString * s.Strings
+}
+
+func (s System) inverters() int {
+	if s.InverterCount <= 0 {
+		return 1
+	}
+	return s.InverterCount
+}
+
+func (s System) Validate() error {
+	if s.ModulesPerString <= 0 || s.Strings <= 0 {
+		return errors.New("system needs at least one module per string and one string")
+	}
+	return s.ValidateInverter()
+}
+
+// ValidateInverter checks everything except module counts, for callers that
+// size the DC side some other way (e.g. sub-arrays).
+func (s System) ValidateInverter() error {
+	if s.InverterCount < 0 {
+		return errors.New("inverter count must not be negative")
+	}
+	if s.Inverter.rating() <= 0 {
+		return errors.New("inverter AC rating must be positive")
+	}
+	if s.ExportLimitW != nil && *s.ExportLimitW < 0 {
+		return errors.New("export limit must not be negative")
+	}
+	if e := s.Inverter.Efficiency; e < 0 || e > 1 {
+		return errors.New("inverter efficiency must be between 0 and 1")
+	}
+	l := s.losses()
+	for _, f := range []float64{l.Wiring, l.Mismatch, l.Soiling, l.Other} {
+		if f < 0 || f >= 1 {
+			return errors.New("losses must be fractions between 0 and 1")
+		}
+	}
+	return nil
+}
+
+func (s System) LossFactor() float64 {
+	return s.losses().Factor()
+}
+
+func (inv Inverter) rating() float64 {
+	if inv.Spec != nil {
+		return inv.Spec.Paco
+	}
+	return inv.ACRatingW
+}
+
+// ACPower converts DC input power to AC output for one inverter. The second
+// return value is the power lost to clipping at the nameplate rating.
+func (inv Inverter) ACPower(dcW float64) (acW, clippedW float64) {
+	if inv.Spec != nil {
+		return inv.Spec.ACPower(dcW)
+	}
+	if dcW <= 0 || inv.ACRatingW <= 0 {
+		return 0, 0
+	}
+	eff := inv.Efficiency
+	if eff == 0 {
+		eff = defaultInverterEfficiency
+	}
+
+	pdc0 := inv.ACRatingW / eff
+	zeta := dcW / pdc0
+	eta := eff / pvwattsReferenceEff * (-0.0162*zeta - 0.0059/zeta + 0.9858)
+	acW = math.Max(eta*dcW, 0)
+
+	if acW > inv.ACRatingW {
+		clippedW = acW - inv.ACRatingW
+		acW = inv.ACRatingW
+	}
+	return acW, clippedW
+}
+
+// ApplySystem turns per-module hourly points into system AC output. EnergyWh
+// and its bands become AC energy; DCWh and ClippedWh carry the DC side.
+func ApplySystem(panel SolarPanelData, sys System, points []HourlyPoint) ([]HourlyPoint, SystemSummary) {
+	n := sys.Modules()
+	dc := ScalePoints(points, float64(n)*sys.LossFactor())
+	return sys.ConvertDC(dc, panel.MaximumPowerPmax*float64(n), n)
+}
+
+// ScalePoints multiplies every energy field by k and records the result as
+// DC energy, e.g. to go from one module to a string of modules.
+func ScalePoints(points []HourlyPoint, k float64) []HourlyPoint {
+	out := make([]HourlyPoint, len(points))
+	var cum, cumLow, cumHigh float64
+	for i, p := range points {
+		p.EnergyWh *= k
+		p.EnergyWhLow *= k
+		p.EnergyWhHigh *= k
+		p.ClearSkyWh *= k
+		p.ShadingLossWh *= k
+		p.BifacialGainWh *= k
+		p.SnowLossWh *= k
+		p.DCWh = p.EnergyWh
+
+		cum += p.EnergyWh
+		cumLow += p.EnergyWhLow
+		cumHigh += p.EnergyWhHigh
+		p.CumulativeWh = cum
+		p.CumulativeLow = cumLow
+		p.CumulativeHigh = cumHigh
+		out[i] = p
+	}
+	return out
+}
+
+// ConvertDC runs DC hourly points (after losses) through the inverters.
+func (s System) ConvertDC(dc []HourlyPoint, dcRatingW float64, modules int) ([]HourlyPoint, SystemSummary) {
+	inv := float64(s.inverters())
+
+	summary := SystemSummary{
+		Modules:    modules,
+		DCRatingW:  dcRatingW,
+		ACRatingW:  s.Inverter.rating() * inv,
+		LossFactor: s.LossFactor(),
+	}
+	if summary.ACRatingW > 0 {
+		summary.DCACRatio = summary.DCRatingW / summary.ACRatingW
+	}
+
+	toAC := func(dcWh float64) (ac, clipped float64) {
+		a, c := s.Inverter.ACPower(dcWh / inv)
+		return a * inv, c * inv
+	}
+
+	out := make([]HourlyPoint, len(dc))
+	var cum, cumLow, cumHigh float64
+	for i, p := range dc {
+		ac, clipped := toAC(p.EnergyWh)
+		low, _ := toAC(p.EnergyWhLow)
+		high, _ := toAC(p.EnergyWhHigh)
+		clearAC, _ := toAC(p.ClearSkyWh)
+		var shadingLoss float64
+		if p.ShadingLossWh > 0 {
+			unshaded, _ := toAC(p.EnergyWh + p.ShadingLossWh)
+			shadingLoss = unshaded - ac
+		}
+		var bifacialGain float64
+		if p.BifacialGainWh > 0 {
+			front, _ := toAC(p.EnergyWh - p.BifacialGainWh)
+			bifacialGain = ac - front
+		}
+		var snowLoss float64
+		if p.SnowLossWh > 0 {
+			uncovered, _ := toAC(p.EnergyWh + p.SnowLossWh)
+			snowLoss = uncovered - ac
+		}
+
+		cum += ac
+		cumLow += low
+		cumHigh += high
+
+		summary.DCWh += p.EnergyWh
+		summary.ACWh += ac
+		summary.ClippedWh += clipped
+		if clipped > 0 {
+			summary.ClippingHours++
+		}
+
+		p.DCWh = p.EnergyWh
+		p.ClippedWh = clipped
+		p.EnergyWh = ac
+		p.EnergyWhLow = low
+		p.EnergyWhHigh = high
+		p.ClearSkyWh = clearAC
+		p.ShadingLossWh = shadingLoss
+		p.BifacialGainWh = bifacialGain
+		p.SnowLossWh = snowLoss
+		p.CumulativeWh = cum
+		p.CumulativeLow = cumLow
+		p.CumulativeHigh = cumHigh
+		out[i] = p
+	}
+	return out, summary
+}
+
+// ApplyExportLimit curtails AC output that would push exports above limitW.
+// With netOfLoad the points carry load and battery flows (see ApplyLoad) and
+// only the export is capped; otherwise all output counts as export. The
+// bands are capped the same way. Each cumulative loses what its own hourly
+// values lost rather than being rebuilt as a running sum, so ensemble daily
+// quantiles (see EnsembleQuantiles) keep their meaning. It returns the energy
+// curtailed.
+func ApplyExportLimit(points []HourlyPoint, limitW float64, netOfLoad bool) float64 {
+	var total, cutLow, cutHigh float64
+	for i := range points {
+		p := &points[i]
+		used := 0.0
+		if netOfLoad {
+			// Output kept on site, whether used directly or stored.
+			used = p.EnergyWh - p.ExportWh
+		}
+		capWh := used + limitW
+
+		p.CurtailedWh = math.Max(p.EnergyWh-capWh, 0)
+		p.EnergyWh -= p.CurtailedWh
+		cutLow += math.Max(p.EnergyWhLow-capWh, 0)
+		cutHigh += math.Max(p.EnergyWhHigh-capWh, 0)
+		p.EnergyWhLow = math.Min(p.EnergyWhLow, capWh)
+		p.EnergyWhHigh = math.Min(p.EnergyWhHigh, capWh)
+		if netOfLoad {
+			p.ExportWh -= p.CurtailedWh
+		}
+		total += p.CurtailedWh
+
+		p.CumulativeWh -= total
+		p.CumulativeLow -= cutLow
+		p.CumulativeHigh -= cutHigh
+	}
+	return total
+}
+
+func Totals(points []HourlyPoint) (base, low, high float64) {
+	if len(points) == 0 {
+		return 0, 0, 0
+	}
+	last := points[len(points)-1]
+	return last.CumulativeWh, last.CumulativeLow, last.CumulativeHigh
+}
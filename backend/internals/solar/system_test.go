@@ -0,0 +1,127 @@
+package solar
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestInverterACPower_Clips(t *testing.T) {
+	inv := Inverter{ACRatingW: 3000, Efficiency: 0.96}
+
+	ac, clipped := inv.ACPower(2000)
+	if ac <= 0 || ac >= 2000 || clipped != 0 {
+		t.Fatalf("expected unclipped output below DC input, got ac=%.2f clipped=%.2f", ac, clipped)
+	}
+
+	ac, clipped = inv.ACPower(4000)
+	almostEqual(t, ac, 3000, 1e-9)
+	if clipped <= 0 {
+		t.Fatalf("expected clipping above nameplate, got %.2f", clipped)
+	}
+}
+
+func TestLossesFactor(t *testing.T) {
+	l := Losses{Wiring: 0.02, Mismatch: 0.02, Soiling: 0.05}
+	almostEqual(t, l.Factor(), 0.98*0.98*0.95, 1e-12)
+}
+
+func TestApplySystem(t *testing.T) {
+	panel := SolarPanelData{MaximumPowerPmax: 400}
+	sys := System{
+		ModulesPerString: 10,
+		Strings:          2,
+		Inverter:         Inverter{ACRatingW: 6000},
+		Losses:           &Losses{},
+	}
+	if err := sys.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	ts := time.Date(2025, time.June, 21, 12, 0, 0, 0, time.UTC)
+	points := []HourlyPoint{
+		{Time: ts, EnergyWh: 100, EnergyWhLow: 90, EnergyWhHigh: 110},
+		{Time: ts.Add(time.Hour), EnergyWh: 350, EnergyWhLow: 315, EnergyWhHigh: 385},
+	}
+
+	out, sum := ApplySystem(panel, sys, points)
+	if sum.Modules != 20 || sum.DCRatingW != 8000 {
+		t.Fatalf("unexpected summary: %+v", sum)
+	}
+	almostEqual(t, out[0].DCWh, 2000, 1e-9)
+	if out[0].ClippedWh != 0 {
+		t.Fatalf("expected no clipping at 2 kW, got %.2f", out[0].ClippedWh)
+	}
+	almostEqual(t, out[1].EnergyWh, 6000, 1e-9)
+	if sum.ClippingHours != 1 || sum.ClippedWh <= 0 {
+		t.Fatalf("expected one clipping hour, got %+v", sum)
+	}
+
+	base, _, _ := Totals(out)
+	almostEqual(t, base, sum.ACWh, 1e-9)
+}
+
+func TestSystemValidate(t *testing.T) {
+	if err := (System{Strings: 1, Inverter: Inverter{ACRatingW: 1}}).Validate(); err == nil {
+		t.Fatal("expected error for zero modules per string")
+	}
+	bad := System{ModulesPerString: 1, Strings: 1, Inverter: Inverter{ACRatingW: 1}, Losses: &Losses{Soiling: 1}}
+	if err := bad.Validate(); err == nil {
+		t.Fatal("expected error for 100% soiling")
+	}
+}
+
+func TestApplyExportLimit(t *testing.T) {
+	day := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	points := []HourlyPoint{
+		{Time: day.Add(12 * time.Hour), EnergyWh: 6000, EnergyWhLow: 5000, EnergyWhHigh: 7000,
+			CumulativeWh: 6000, CumulativeLow: 5000, CumulativeHigh: 7000},
+		{Time: day.Add(13 * time.Hour), EnergyWh: 3000, EnergyWhLow: 2500, EnergyWhHigh: 3500,
+			CumulativeWh: 9000, CumulativeLow: 7500, CumulativeHigh: 10500},
+	}
+
+	export := make([]HourlyPoint, len(points))
+	copy(export, points)
+	curtailed := ApplyExportLimit(export, 4000, false)
+	almostEqual(t, curtailed, 2000, 1e-9)
+	almostEqual(t, export[0].EnergyWh, 4000, 1e-9)
+	almostEqual(t, export[0].EnergyWhHigh, 4000, 1e-9)
+	almostEqual(t, export[1].CurtailedWh, 0, 1e-9)
+	almostEqual(t, export[1].CumulativeWh, 7000, 1e-9)
+	almostEqual(t, export[1].CumulativeHigh, 7500, 1e-9)
+
+	// Zero export: only what the house uses is kept.
+	ApplyLoad(points, flatLoad(1000))
+	curtailed = ApplyExportLimit(points, 0, true)
+	almostEqual(t, curtailed, 7000, 1e-9)
+	almostEqual(t, points[0].EnergyWh, 1000, 1e-9)
+	almostEqual(t, points[0].ExportWh, 0, 1e-9)
+	almostEqual(t, points[0].EnergyWhLow, 1000, 1e-9)
+}
+
+func TestApplyExportLimit_KeepsDailyQuantiles(t *testing.T) {
+	day := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	// Daily quantiles, not running sums of the hourly bands.
+	points := []HourlyPoint{
+		{Time: day.Add(12 * time.Hour), EnergyWh: 5000, EnergyWhLow: 3000, EnergyWhHigh: 6000,
+			CumulativeWh: 5000, CumulativeLow: 4000, CumulativeHigh: 5500},
+		{Time: day.Add(13 * time.Hour), EnergyWh: 2000, EnergyWhLow: 1000, EnergyWhHigh: 3000,
+			CumulativeWh: 7000, CumulativeLow: 5500, CumulativeHigh: 8000},
+	}
+
+	ApplyExportLimit(points, 4000, false)
+	almostEqual(t, points[1].CumulativeWh, 6000, 1e-9)
+	almostEqual(t, points[1].CumulativeLow, 5500, 1e-9)
+	almostEqual(t, points[1].CumulativeHigh, 6000, 1e-9)
+}
+
+func TestInverter_SpecNotDecoded(t *testing.T) {
+	var inv Inverter
+	in := `{"model":"X","acRatingW":3000,"spec":{"paco":3000,"pdco":-1,"c0":5}}`
+	if err := json.Unmarshal([]byte(in), &inv); err != nil {
+		t.Fatal(err)
+	}
+	if inv.Spec != nil {
+		t.Fatalf("expected a posted spec to be ignored, got %+v", inv.Spec)
+	}
+}
@@ -0,0 +1,103 @@
+package solar
+
+import (
+	"errors"
+	"math"
+)
+
+type TrackerType string
+
+const (
+	TrackerSingleAxis TrackerType = "single-axis"
+	TrackerDualAxis   TrackerType = "dual-axis"
+)
+
+// Tracker turns the array to follow the sun. A single-axis tracker rotates
+// about a horizontal axis pointing AxisAzimuth (180 for a north-south axis)
+// by up to MaxRotation degrees either way. With Backtrack set it turns back
+// from the ideal angle so rows at ground coverage ratio GCR do not shade one
+// another. A dual-axis tracker faces the sun directly.
+type Tracker struct {
+	Type        TrackerType `json:"type"`
+	AxisAzimuth float64     `json:"axisAzimuth,omitempty"`
+	MaxRotation float64     `json:"maxRotation,omitempty"`
+	GCR         float64     `json:"gcr,omitempty"`
+	Backtrack   bool        `json:"backtrack,omitempty"`
+}
+
+const (
+	defaultMaxRotation = 60
+	defaultGCR         = 0.35
+)
+
+func (t Tracker) Validate() error {
+	switch t.Type {
+	case TrackerSingleAxis, TrackerDualAxis:
+	default:
+		return errors.New("unknown tracker type")
+	}
+	if t.AxisAzimuth < 0 || t.AxisAzimuth >= 360 {
+		return errors.New("tracker axis azimuth out of range")
+	}
+	if t.MaxRotation < 0 || t.MaxRotation > 90 {
+		return errors.New("tracker max rotation must be between 0 and 90")
+	}
+	if t.GCR < 0 || t.GCR >= 1 {
+		return errors.New("ground coverage ratio must be between 0 and 1")
+	}
+	return nil
+}
+
+// Orientation returns the surface tilt and azimuth for the sun position.
+// Below the horizon the tracker stows flat.
+func (t Tracker) Orientation(sun SunPosition) (tilt, azimuth float64) {
+	if sun.Elevation <= 0 {
+		return 0, t.AxisAzimuth
+	}
+	if t.Type == TrackerDualAxis {
+		return 90 - sun.Elevation, sun.Azimuth
+	}
+
+	rot := t.singleAxisRotation(sun)
+	azimuth = wrap360(t.AxisAzimuth + 90)
+	if rot < 0 {
+		azimuth = wrap360(t.AxisAzimuth - 90)
+	}
+	return math.Abs(rot), azimuth
+}
+
+// singleAxisRotation follows Lorenzo et al. (2011) for a horizontal axis:
+// positive angles tilt the surface towards AxisAzimuth + 90°.
+func (t Tracker) singleAxisRotation(sun SunPosition) float64 {
+	maxRot := t.MaxRotation
+	if maxRot == 0 {
+		maxRot = defaultMaxRotation
+	}
+
+	s := sunVector(sun)
+	a := deg2rad(t.AxisAzimuth)
+	// Sun component across the axis, towards AxisAzimuth + 90°.
+	x := s.x*math.Cos(a) - s.y*math.Sin(a)
+	rot := rad2deg(math.Atan2(x, s.z))
+
+	if t.Backtrack {
+		gcr := t.GCR
+		if gcr == 0 {
+			gcr = defaultGCR
+		}
+		if c := math.Abs(math.Cos(deg2rad(rot))) / gcr; c < 1 {
+			rot -= math.Copysign(rad2deg(math.Acos(c)), rot)
+		}
+	}
+	return math.Max(-maxRot, math.Min(rot, maxRot))
+}
+
+// orientedAt returns the array with the tracker's tilt and azimuth for the
+// hour; fixed arrays are returned unchanged.
+func (arr Array) orientedAt(sun SunPosition) Array {
+	if arr.Tracker == nil {
+		return arr
+	}
+	arr.Tilt, arr.Azimuth = arr.Tracker.Orientation(sun)
+	return arr
+}
@@ -0,0 +1,96 @@
+package solar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joseph-gunnarsson/solar-cast/internals/clients"
+)
+
+func TestTrackerOrientation(t *testing.T) {
+	ns := Tracker{Type: TrackerSingleAxis, AxisAzimuth: 180, MaxRotation: 60}
+
+	// Noon sun due south: a north-south axis lies flat.
+	tilt, _ := ns.Orientation(SunPosition{Elevation: 60, Azimuth: 180})
+	almostEqual(t, tilt, 0, 1e-9)
+
+	// Morning sun due east at 30°: the ideal angle is the zenith, 60°.
+	tilt, az := ns.Orientation(SunPosition{Elevation: 30, Azimuth: 90})
+	almostEqual(t, tilt, 60, 1e-9)
+	almostEqual(t, az, 90, 1e-9)
+
+	// Low sun hits the rotation limit.
+	tilt, az = ns.Orientation(SunPosition{Elevation: 10, Azimuth: 270})
+	almostEqual(t, tilt, 60, 1e-9)
+	almostEqual(t, az, 270, 1e-9)
+
+	// Backtracking turns flatter as the sun drops.
+	back := ns
+	back.Backtrack, back.GCR = true, 0.4
+	low, _ := back.Orientation(SunPosition{Elevation: 10, Azimuth: 90})
+	lower, _ := back.Orientation(SunPosition{Elevation: 5, Azimuth: 90})
+	if low >= 60 || lower >= low {
+		t.Fatalf("expected backtracking to reduce rotation: %.1f at 10°, %.1f at 5°", low, lower)
+	}
+
+	dual := Tracker{Type: TrackerDualAxis}
+	tilt, az = dual.Orientation(SunPosition{Elevation: 25, Azimuth: 130})
+	almostEqual(t, tilt, 65, 1e-9)
+	almostEqual(t, az, 130, 1e-9)
+
+	tilt, _ = dual.Orientation(SunPosition{Elevation: -3, Azimuth: 300})
+	almostEqual(t, tilt, 0, 1e-9)
+}
+
+func TestTrackerValidate(t *testing.T) {
+	if err := (Tracker{Type: TrackerSingleAxis, GCR: 0.4}).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tr := range []Tracker{{Type: "azimuthal"}, {Type: TrackerSingleAxis, MaxRotation: 120}, {Type: TrackerSingleAxis, GCR: 1.5}} {
+		if err := tr.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", tr)
+		}
+	}
+}
+
+func TestCalculateHourlyOutputForArray_Tracker(t *testing.T) {
+	panel := SolarPanelData{MaximumPowerPmax: 400, TemperatureCoefficientPmax: -0.004, NOCT_Temp: 45}
+	var wp clients.WeatherPack
+	for h := 6; h <= 19; h++ {
+		wp.Hours = append(wp.Hours, clients.HourWeather{
+			Time:          time.Date(2025, time.June, 21, h, 0, 0, 0, time.UTC),
+			AmbientTemp:   20,
+			IrradianceGHI: 600,
+			IrradianceDNI: 700,
+			IrradianceDHI: 100,
+			HasComponents: true,
+		})
+	}
+
+	fixed := Array{Tilt: 30, Azimuth: 180, Albedo: DefaultAlbedo}
+	_, fixedWh, _, _, err := CalculateHourlyOutputForArray(panel, wp, 40, 0, fixed)
+	if err != nil {
+		t.Fatalf("fixed: %v", err)
+	}
+
+	single := fixed
+	single.Tracker = &Tracker{Type: TrackerSingleAxis, AxisAzimuth: 180}
+	points, singleWh, _, _, err := CalculateHourlyOutputForArray(panel, wp, 40, 0, single)
+	if err != nil {
+		t.Fatalf("single: %v", err)
+	}
+
+	dual := fixed
+	dual.Tracker = &Tracker{Type: TrackerDualAxis}
+	_, dualWh, _, _, err := CalculateHourlyOutputForArray(panel, wp, 40, 0, dual)
+	if err != nil {
+		t.Fatalf("dual: %v", err)
+	}
+
+	if !(fixedWh < singleWh && singleWh <= dualWh) {
+		t.Fatalf("expected fixed < single-axis <= dual-axis, got %.0f, %.0f, %.0f", fixedWh, singleWh, dualWh)
+	}
+	if points[0].SurfaceAzimuth != 90 || points[0].SurfaceTilt <= 0 {
+		t.Fatalf("expected an east-facing morning orientation: %+v", points[0])
+	}
+}